@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"math/big"
 	"os"
 	"runtime/pprof"
 	"time"
@@ -36,6 +37,47 @@ func addEndFlag(flags []cli.Flag, required bool) []cli.Flag {
 	})
 }
 
+func addTimeRangeFlags(flags []cli.Flag) []cli.Flag {
+	return append(flags,
+		&cli.StringFlag{
+			Name:  "from",
+			Value: "",
+			Usage: "Start date/timestamp, inclusive (e.g. 2020-03-01)",
+		},
+		&cli.StringFlag{
+			Name:  "to",
+			Value: "",
+			Usage: "End date/timestamp, exclusive (e.g. 2020-04-01)",
+		})
+}
+
+func getTimeRange(c *cli.Context) (timeRange core.TimeRange, err error) {
+	if from := c.String("from"); from != "" {
+		if timeRange.From, err = core.ParseTime(from); err != nil {
+			return
+		}
+	}
+	if to := c.String("to"); to != "" {
+		timeRange.To, err = core.ParseTime(to)
+	}
+	return
+}
+
+func addFilterFlag(flags []cli.Flag) []cli.Flag {
+	return append(flags, &cli.StringFlag{
+		Name:  "filter",
+		Value: "",
+		Usage: "Only process the actions matching the filter (e.g. 'name == \"transfer\"')",
+	})
+}
+
+func getFilter(c *cli.Context) (*core.Filter, error) {
+	if c.String("filter") == "" {
+		return nil, nil
+	}
+	return core.ParseFilter(c.String("filter"))
+}
+
 func addOutputFlag(flags []cli.Flag) []cli.Flag {
 	return append(flags, &cli.StringFlag{
 		Name:     "output",
@@ -58,7 +100,7 @@ func addActionPropertyFlag(flags []cli.Flag) []cli.Flag {
 	return append(flags, &cli.StringFlag{
 		Name:  "by",
 		Value: "name",
-		Usage: "Property to group the actions by",
+		Usage: "Properties to group the actions by, separated by commas (name, sender, receiver, hour, weekday, status, data.<field>, category[.<property>])",
 	})
 }
 
@@ -80,12 +122,60 @@ func addPatternFlag(flags []cli.Flag) []cli.Flag {
 	})
 }
 
+func addOptionalPatternFlag(flags []cli.Flag) []cli.Flag {
+	return append(flags, &cli.StringFlag{
+		Name:    "pattern",
+		Aliases: []string{"p"},
+		Value:   "",
+		Usage:   "Patterns of files to use, the node is used if empty",
+	})
+}
+
+func addTimeFlag(flags []cli.Flag) []cli.Flag {
+	return append(flags, &cli.StringFlag{
+		Name:     "time",
+		Aliases:  []string{"t"},
+		Usage:    "Date/timestamp to resolve (e.g. 2020-03-01)",
+		Required: true,
+	})
+}
+
 func addGroupDurationFlag(flags []cli.Flag) []cli.Flag {
 	return append(flags, &cli.StringFlag{
 		Name:    "duration",
 		Aliases: []string{"d"},
 		Value:   "6h",
-		Usage:   "Duration to group by when counting",
+		Usage:   "Duration to group by when counting (e.g. 6h, day, week, month)",
+	})
+}
+
+func addTimePartitionFlag(flags []cli.Flag) []cli.Flag {
+	return append(flags, &cli.StringFlag{
+		Name:  "period",
+		Value: "month",
+		Usage: "Period of each output file (day or month)",
+	})
+}
+
+func addResultsLimitsFlags(flags []cli.Flag) []cli.Flag {
+	return append(flags,
+		&cli.IntFlag{
+			Name:  "top",
+			Value: core.DefaultTopLevelResults,
+			Usage: "Number of groups to output, 0 for unlimited",
+		},
+		&cli.IntFlag{
+			Name:  "nested-top",
+			Value: core.DefaultNestedResults,
+			Usage: "Number of results to output in each group, 0 for unlimited",
+		})
+}
+
+func addExportFlag(flags []cli.Flag) []cli.Flag {
+	return append(flags, &cli.StringFlag{
+		Name:  "export",
+		Value: "",
+		Usage: "Optional CSV file where to export the first block, time and action of each account",
 	})
 }
 
@@ -107,6 +197,30 @@ func addCpuProfileFlag(flags []cli.Flag) []cli.Flag {
 	})
 }
 
+func addCategoriesFlag(flags []cli.Flag) []cli.Flag {
+	return append(flags, &cli.StringSliceFlag{
+		Name:  "categories",
+		Usage: "Files mapping accounts or other properties to categories, used by the category properties",
+	})
+}
+
+// getCategories loads the files of the global categories flag, if any
+func getCategories(c *cli.Context) (*core.Categories, error) {
+	filenames := c.StringSlice("categories")
+	if len(filenames) == 0 {
+		return nil, nil
+	}
+	return core.LoadCategories(filenames...)
+}
+
+func getActionProperties(c *cli.Context) (core.ActionProperties, error) {
+	categories, err := getCategories(c)
+	if err != nil {
+		return nil, err
+	}
+	return core.GetActionProperties(c.String("by"), categories)
+}
+
 func makeAction(f func(*cli.Context) error) func(*cli.Context) error {
 	return func(c *cli.Context) error {
 		cpuProfile := c.String("cpu-profile")
@@ -148,12 +262,20 @@ func addCommonCommands(blockchain core.Blockchain, commands []*cli.Command) []*c
 		},
 		{
 			Name:  "count-transactions",
-			Flags: addPatternFlag(addRangeFlags(nil, false)),
+			Flags: addFilterFlag(addTimeRangeFlags(addPatternFlag(addRangeFlags(nil, false)))),
 			Usage: "Count the number of transactions in the data",
 			Action: makeAction(func(c *cli.Context) error {
+				timeRange, err := getTimeRange(c)
+				if err != nil {
+					return err
+				}
+				filter, err := getFilter(c)
+				if err != nil {
+					return err
+				}
 				count, err := processor.CountTransactions(
 					blockchain, c.String("pattern"),
-					c.Uint64("start"), c.Uint64("end"))
+					c.Uint64("start"), c.Uint64("end"), timeRange, filter)
 				if err != nil {
 					return err
 				}
@@ -163,66 +285,637 @@ func addCommonCommands(blockchain core.Blockchain, commands []*cli.Command) []*c
 		},
 		{
 			Name: "group-actions",
-			Flags: addDetailedFlag(addActionPropertyFlag(
-				addPatternFlag(addOutputFlag(addRangeFlags(nil, false))))),
+			Flags: addResultsLimitsFlags(addDetailedFlag(addActionPropertyFlag(addFilterFlag(
+				addTimeRangeFlags(addPatternFlag(addOutputFlag(addRangeFlags(nil, false)))))))),
 			Usage: "Count and groups the number of \"actions\" in the data",
 			Action: makeAction(func(c *cli.Context) error {
-				actionProperty, err := core.GetActionProperty(c.String("by"))
+				timeRange, err := getTimeRange(c)
+				if err != nil {
+					return err
+				}
+				filter, err := getFilter(c)
+				if err != nil {
+					return err
+				}
+				actionProperties, err := getActionProperties(c)
 				if err != nil {
 					return err
 				}
 				counts, err := processor.GroupActions(
 					blockchain, c.String("pattern"),
-					c.Uint64("start"), c.Uint64("end"),
-					actionProperty, c.Bool("detailed"))
+					c.Uint64("start"), c.Uint64("end"), timeRange, filter,
+					actionProperties, c.Bool("detailed"))
 				if err != nil {
 					return err
 				}
+				counts.SetResultsLimits(c.Int("top"), c.Int("nested-top"))
 				return core.Persist(counts, c.String("output"))
 			}),
 		},
 		{
 			Name: "group-actions-over-time",
-			Flags: addActionPropertyFlag(addGroupDurationFlag(
-				addPatternFlag(addOutputFlag(addRangeFlags(nil, false))))),
+			Flags: addResultsLimitsFlags(addActionPropertyFlag(addGroupDurationFlag(addFilterFlag(
+				addTimeRangeFlags(addPatternFlag(addOutputFlag(addRangeFlags(nil, false)))))))),
 			Usage: "Count and groups per time the number of \"actions\" in the data",
 			Action: makeAction(func(c *cli.Context) error {
-				duration, err := time.ParseDuration(c.String("duration"))
+				timeRange, err := getTimeRange(c)
 				if err != nil {
 					return err
 				}
-				actionProperty, err := core.GetActionProperty(c.String("by"))
+				filter, err := getFilter(c)
+				if err != nil {
+					return err
+				}
+				duration, err := core.ParseDuration(c.String("duration"))
+				if err != nil {
+					return err
+				}
+				actionProperties, err := getActionProperties(c)
 				if err != nil {
 					return err
 				}
 				counts, err := processor.CountActionsOverTime(
 					blockchain, c.String("pattern"),
-					c.Uint64("start"), c.Uint64("end"),
-					duration, actionProperty)
+					c.Uint64("start"), c.Uint64("end"), timeRange, filter,
+					duration, actionProperties)
 				if err != nil {
 					return err
 				}
+				counts.SetResultsLimits(c.Int("top"), c.Int("nested-top"))
 				return core.Persist(counts, c.String("output"))
 			}),
 		},
 		{
-			Name:  "count-transactions-over-time",
-			Flags: addGroupDurationFlag(addPatternFlag(addOutputFlag(addRangeFlags(nil, false)))),
+			Name: "count-transactions-over-time",
+			Flags: addGroupDurationFlag(addFilterFlag(addTimeRangeFlags(
+				addPatternFlag(addOutputFlag(addRangeFlags(nil, false)))))),
 			Usage: "Count number of \"transactions\" over time in the data",
 			Action: makeAction(func(c *cli.Context) error {
-				duration, err := time.ParseDuration(c.String("duration"))
+				timeRange, err := getTimeRange(c)
+				if err != nil {
+					return err
+				}
+				filter, err := getFilter(c)
+				if err != nil {
+					return err
+				}
+				duration, err := core.ParseDuration(c.String("duration"))
 				if err != nil {
 					return err
 				}
 				counts, err := processor.CountTransactionsOverTime(
 					blockchain, c.String("pattern"),
-					c.Uint64("start"), c.Uint64("end"), duration)
+					c.Uint64("start"), c.Uint64("end"), timeRange, filter, duration)
+				if err != nil {
+					return err
+				}
+				return core.Persist(counts, c.String("output"))
+			}),
+		},
+		{
+			Name: "active-accounts-over-time",
+			Flags: addGroupDurationFlag(addFilterFlag(addTimeRangeFlags(
+				addPatternFlag(addOutputFlag(addRangeFlags(nil, false)))))),
+			Usage: "Count the number of distinct senders, receivers and participants over time",
+			Action: makeAction(func(c *cli.Context) error {
+				timeRange, err := getTimeRange(c)
+				if err != nil {
+					return err
+				}
+				filter, err := getFilter(c)
+				if err != nil {
+					return err
+				}
+				duration, err := core.ParseDuration(c.String("duration"))
+				if err != nil {
+					return err
+				}
+				counts, err := processor.CountActiveAccountsOverTime(
+					blockchain, c.String("pattern"),
+					c.Uint64("start"), c.Uint64("end"), timeRange, filter, duration)
+				if err != nil {
+					return err
+				}
+				return core.Persist(counts, c.String("output"))
+			}),
+		},
+		{
+			Name: "new-accounts-over-time",
+			Flags: addExportFlag(addGroupDurationFlag(addFilterFlag(addTimeRangeFlags(
+				addPatternFlag(addOutputFlag(addRangeFlags(nil, false))))))),
+			Usage: "Count the number of accounts seen for the first time over time",
+			Action: makeAction(func(c *cli.Context) error {
+				timeRange, err := getTimeRange(c)
+				if err != nil {
+					return err
+				}
+				filter, err := getFilter(c)
+				if err != nil {
+					return err
+				}
+				duration, err := core.ParseDuration(c.String("duration"))
+				if err != nil {
+					return err
+				}
+				counts, err := processor.CountNewAccountsOverTime(
+					blockchain, c.String("pattern"),
+					c.Uint64("start"), c.Uint64("end"), timeRange, filter,
+					duration, c.String("export"))
 				if err != nil {
 					return err
 				}
 				return core.Persist(counts, c.String("output"))
 			}),
 		},
+		{
+			Name: "retention",
+			Flags: addGroupDurationFlag(addFilterFlag(addTimeRangeFlags(
+				addPatternFlag(addOutputFlag(addRangeFlags(nil, false)))))),
+			Usage: "Compute the retention of the cohorts of accounts first active in each period",
+			Action: makeAction(func(c *cli.Context) error {
+				timeRange, err := getTimeRange(c)
+				if err != nil {
+					return err
+				}
+				filter, err := getFilter(c)
+				if err != nil {
+					return err
+				}
+				duration, err := core.ParseDuration(c.String("duration"))
+				if err != nil {
+					return err
+				}
+				retention, err := processor.ComputeRetention(
+					blockchain, c.String("pattern"),
+					c.Uint64("start"), c.Uint64("end"), timeRange, filter, duration)
+				if err != nil {
+					return err
+				}
+				return core.Persist(retention, c.String("output"))
+			}),
+		},
+		{
+			Name: "export-graph",
+			Flags: append(addFilterFlag(addTimeRangeFlags(
+				addPatternFlag(addOutputFlag(addRangeFlags(nil, false))))),
+				&cli.StringFlag{
+					Name:  "format",
+					Usage: "Format of the graph: csv, graphml or gexf (default: inferred from output)",
+				},
+				&cli.Uint64Flag{
+					Name:  "min-weight",
+					Value: 1,
+					Usage: "Minimum number of actions for an edge to be exported",
+				},
+				&cli.BoolFlag{
+					Name:  "by-name",
+					Usage: "Create a separate edge for each action name",
+				},
+			),
+			Usage: "Export the weighted sender to receiver graph of the actions",
+			Action: makeAction(func(c *cli.Context) error {
+				timeRange, err := getTimeRange(c)
+				if err != nil {
+					return err
+				}
+				filter, err := getFilter(c)
+				if err != nil {
+					return err
+				}
+				var format core.GraphFormat
+				if c.String("format") != "" {
+					format, err = core.GetGraphFormat(c.String("format"))
+				} else {
+					format, err = core.InferGraphFormat(c.String("output"))
+				}
+				if err != nil {
+					return err
+				}
+				return processor.ExportGraph(
+					blockchain, c.String("pattern"),
+					c.Uint64("start"), c.Uint64("end"), timeRange, filter,
+					c.Bool("by-name"), c.Uint64("min-weight"), format, c.String("output"))
+			}),
+		},
+		{
+			Name: "graph-stats",
+			Flags: append(addFilterFlag(addTimeRangeFlags(
+				addPatternFlag(addOutputFlag(addRangeFlags(nil, false))))),
+				&cli.IntFlag{
+					Name:  "top",
+					Value: core.DefaultNestedResults,
+					Usage: "Number of top accounts to output, 0 for unlimited",
+				},
+			),
+			Usage: "Compute degree, PageRank, component and reciprocity statistics of the interaction graph",
+			Action: makeAction(func(c *cli.Context) error {
+				timeRange, err := getTimeRange(c)
+				if err != nil {
+					return err
+				}
+				filter, err := getFilter(c)
+				if err != nil {
+					return err
+				}
+				stats, err := processor.ComputeGraphStats(
+					blockchain, c.String("pattern"),
+					c.Uint64("start"), c.Uint64("end"), timeRange, filter, c.Int("top"))
+				if err != nil {
+					return err
+				}
+				return core.Persist(stats, c.String("output"))
+			}),
+		},
+		{
+			Name: "concentration",
+			Flags: append(addGroupDurationFlag(addFilterFlag(addTimeRangeFlags(
+				addPatternFlag(addOutputFlag(addRangeFlags(nil, false)))))),
+				&cli.StringFlag{
+					Name:  "by",
+					Value: core.DefaultConcentrationProperties.String(),
+					Usage: "Properties for which to compute the concentration, separated by commas",
+				},
+				&cli.IntSliceFlag{
+					Name:  "top-n",
+					Value: cli.NewIntSlice(core.DefaultConcentrationTopN...),
+					Usage: "Numbers of top accounts for which to compute the share of actions",
+				},
+			),
+			Usage: "Compute the Gini, HHI, top-N share and Nakamoto coefficient of the actions over time",
+			Action: makeAction(func(c *cli.Context) error {
+				timeRange, err := getTimeRange(c)
+				if err != nil {
+					return err
+				}
+				filter, err := getFilter(c)
+				if err != nil {
+					return err
+				}
+				duration, err := core.ParseDuration(c.String("duration"))
+				if err != nil {
+					return err
+				}
+				by, err := getActionProperties(c)
+				if err != nil {
+					return err
+				}
+				concentration, err := processor.ComputeConcentration(
+					blockchain, c.String("pattern"),
+					c.Uint64("start"), c.Uint64("end"), timeRange, filter,
+					duration, by, c.IntSlice("top-n"))
+				if err != nil {
+					return err
+				}
+				return core.Persist(concentration, c.String("output"))
+			}),
+		},
+		{
+			Name: "producers",
+			Flags: append(addGroupDurationFlag(addTimeRangeFlags(
+				addPatternFlag(addOutputFlag(addRangeFlags(nil, false))))),
+				&cli.IntSliceFlag{
+					Name:  "top-n",
+					Value: cli.NewIntSlice(core.DefaultConcentrationTopN...),
+					Usage: "Numbers of top producers for which to compute the share of blocks",
+				},
+			),
+			Usage: "Count the blocks and missed slots of each block producer over time",
+			Action: makeAction(func(c *cli.Context) error {
+				timeRange, err := getTimeRange(c)
+				if err != nil {
+					return err
+				}
+				duration, err := core.ParseDuration(c.String("duration"))
+				if err != nil {
+					return err
+				}
+				producers, err := processor.CountProducersOverTime(
+					blockchain, c.String("pattern"),
+					c.Uint64("start"), c.Uint64("end"), timeRange,
+					duration, c.IntSlice("top-n"))
+				if err != nil {
+					return err
+				}
+				return core.Persist(producers, c.String("output"))
+			}),
+		},
+		{
+			Name: "block-times",
+			Flags: append(addGroupDurationFlag(addTimeRangeFlags(
+				addPatternFlag(addOutputFlag(addRangeFlags(nil, false))))),
+				&cli.Float64Flag{
+					Name:  "stall-factor",
+					Value: core.DefaultStallFactor,
+					Usage: "Number of times the median block interval after which an interval is reported as a stall",
+				},
+			),
+			Usage: "Compute block intervals, empty blocks ratio and throughput over time",
+			Action: makeAction(func(c *cli.Context) error {
+				timeRange, err := getTimeRange(c)
+				if err != nil {
+					return err
+				}
+				duration, err := core.ParseDuration(c.String("duration"))
+				if err != nil {
+					return err
+				}
+				blockTimes, err := processor.ComputeBlockTimes(
+					blockchain, c.String("pattern"),
+					c.Uint64("start"), c.Uint64("end"), timeRange,
+					duration, c.Float64("stall-factor"))
+				if err != nil {
+					return err
+				}
+				return core.Persist(blockTimes, c.String("output"))
+			}),
+		},
+		{
+			Name: "distribution",
+			Flags: append(addFilterFlag(addTimeRangeFlags(
+				addPatternFlag(addOutputFlag(addRangeFlags(nil, false))))),
+				&cli.StringFlag{
+					Name:    "duration",
+					Aliases: []string{"d"},
+					Usage:   "Optional duration to also compute the distributions over time (e.g. 6h, day, month)",
+				},
+			),
+			Usage: "Compute histograms and percentiles of the transactions and actions per block",
+			Action: makeAction(func(c *cli.Context) error {
+				timeRange, err := getTimeRange(c)
+				if err != nil {
+					return err
+				}
+				filter, err := getFilter(c)
+				if err != nil {
+					return err
+				}
+				var duration core.Duration
+				if c.String("duration") != "" {
+					if duration, err = core.ParseDuration(c.String("duration")); err != nil {
+						return err
+					}
+				}
+				distribution, err := processor.ComputeDistribution(
+					blockchain, c.String("pattern"),
+					c.Uint64("start"), c.Uint64("end"), timeRange, filter, duration)
+				if err != nil {
+					return err
+				}
+				return core.Persist(distribution, c.String("output"))
+			}),
+		},
+		{
+			Name: "export-transfers",
+			Flags: append(addFilterFlag(addTimeRangeFlags(
+				addPatternFlag(addOutputFlag(addRangeFlags(nil, false))))),
+				&cli.StringFlag{
+					Name:  "format",
+					Usage: "Format of the transfers: csv, jsonl or parquet (default: inferred from output)",
+				},
+			),
+			Usage: "Export the transfers, including token transfers, to a CSV, JSONL or Parquet file",
+			Action: makeAction(func(c *cli.Context) error {
+				timeRange, err := getTimeRange(c)
+				if err != nil {
+					return err
+				}
+				filter, err := getFilter(c)
+				if err != nil {
+					return err
+				}
+				var format core.TransferFormat
+				if c.String("format") != "" {
+					format, err = core.GetTransferFormat(c.String("format"))
+				} else {
+					format, err = core.InferTransferFormat(c.String("output"))
+				}
+				if err != nil {
+					return err
+				}
+				return processor.ExportTransfers(
+					blockchain, c.String("pattern"),
+					c.Uint64("start"), c.Uint64("end"), timeRange, filter, format, c.String("output"))
+			}),
+		},
+		{
+			Name: "transfer-volume",
+			Flags: append(addFilterFlag(addTimeRangeFlags(
+				addPatternFlag(addOutputFlag(addRangeFlags(nil, false))))),
+				&cli.StringFlag{
+					Name:    "duration",
+					Aliases: []string{"d"},
+					Usage:   "Optional duration to also compute the volume over time (e.g. 6h, day, month)",
+				},
+				&cli.IntFlag{
+					Name:  "top-accounts",
+					Value: core.DefaultNestedResults,
+					Usage: "Number of accounts to output for each asset, 0 for unlimited",
+				},
+			),
+			Usage: "Sum the amounts transferred per asset, account and time",
+			Action: makeAction(func(c *cli.Context) error {
+				timeRange, err := getTimeRange(c)
+				if err != nil {
+					return err
+				}
+				filter, err := getFilter(c)
+				if err != nil {
+					return err
+				}
+				var duration core.Duration
+				if c.String("duration") != "" {
+					if duration, err = core.ParseDuration(c.String("duration")); err != nil {
+						return err
+					}
+				}
+				volume, err := processor.ComputeTransferVolume(
+					blockchain, c.String("pattern"),
+					c.Uint64("start"), c.Uint64("end"), timeRange, filter,
+					duration, c.Int("top-accounts"))
+				if err != nil {
+					return err
+				}
+				return core.Persist(volume, c.String("output"))
+			}),
+		},
+		{
+			Name: "failure-rate-over-time",
+			Flags: addGroupDurationFlag(addFilterFlag(
+				addTimeRangeFlags(addPatternFlag(addOutputFlag(addRangeFlags(nil, false)))))),
+			Usage: "Count the successful and failed actions over time",
+			Action: makeAction(func(c *cli.Context) error {
+				timeRange, err := getTimeRange(c)
+				if err != nil {
+					return err
+				}
+				filter, err := getFilter(c)
+				if err != nil {
+					return err
+				}
+				duration, err := core.ParseDuration(c.String("duration"))
+				if err != nil {
+					return err
+				}
+				failureRate, err := processor.ComputeFailureRateOverTime(
+					blockchain, c.String("pattern"),
+					c.Uint64("start"), c.Uint64("end"), timeRange, filter, duration)
+				if err != nil {
+					return err
+				}
+				return core.Persist(failureRate, c.String("output"))
+			}),
+		},
+		{
+			Name: "fees-over-time",
+			Flags: append(addGroupDurationFlag(addFilterFlag(
+				addTimeRangeFlags(addPatternFlag(addOutputFlag(addRangeFlags(nil, false)))))),
+				&cli.IntFlag{
+					Name:  "top-senders",
+					Value: core.DefaultNestedResults,
+					Usage: "Number of senders to output for each resource, 0 for unlimited",
+				},
+			),
+			Usage: "Compute the distribution of fees and resource usage over time and per sender",
+			Action: makeAction(func(c *cli.Context) error {
+				timeRange, err := getTimeRange(c)
+				if err != nil {
+					return err
+				}
+				filter, err := getFilter(c)
+				if err != nil {
+					return err
+				}
+				duration, err := core.ParseDuration(c.String("duration"))
+				if err != nil {
+					return err
+				}
+				fees, err := processor.ComputeFeesOverTime(
+					blockchain, c.String("pattern"),
+					c.Uint64("start"), c.Uint64("end"), timeRange, filter,
+					duration, c.Int("top-senders"))
+				if err != nil {
+					return err
+				}
+				return core.Persist(fees, c.String("output"))
+			}),
+		},
+		{
+			Name: "actions-per-transaction",
+			Flags: append(addFilterFlag(addTimeRangeFlags(
+				addPatternFlag(addOutputFlag(addRangeFlags(nil, false))))),
+				&cli.IntFlag{
+					Name:  "top-patterns",
+					Value: core.DefaultNestedResults,
+					Usage: "Number of multi-action transaction patterns to output, 0 for unlimited",
+				},
+			),
+			Usage: "Compute the distribution of actions per transaction and the patterns of multi-action transactions",
+			Action: makeAction(func(c *cli.Context) error {
+				timeRange, err := getTimeRange(c)
+				if err != nil {
+					return err
+				}
+				filter, err := getFilter(c)
+				if err != nil {
+					return err
+				}
+				actionsPerTransaction, err := processor.ComputeActionsPerTransaction(
+					blockchain, c.String("pattern"),
+					c.Uint64("start"), c.Uint64("end"), timeRange, filter, c.Int("top-patterns"))
+				if err != nil {
+					return err
+				}
+				return core.Persist(actionsPerTransaction, c.String("output"))
+			}),
+		},
+		{
+			Name: "inline-actions-over-time",
+			Flags: addGroupDurationFlag(addFilterFlag(
+				addTimeRangeFlags(addPatternFlag(addOutputFlag(addRangeFlags(nil, false)))))),
+			Usage: "Count the top-level and inline actions over time",
+			Action: makeAction(func(c *cli.Context) error {
+				timeRange, err := getTimeRange(c)
+				if err != nil {
+					return err
+				}
+				filter, err := getFilter(c)
+				if err != nil {
+					return err
+				}
+				duration, err := core.ParseDuration(c.String("duration"))
+				if err != nil {
+					return err
+				}
+				inlineActions, err := processor.ComputeInlineActionsOverTime(
+					blockchain, c.String("pattern"),
+					c.Uint64("start"), c.Uint64("end"), timeRange, filter, duration)
+				if err != nil {
+					return err
+				}
+				return core.Persist(inlineActions, c.String("output"))
+			}),
+		},
+		{
+			Name: "balances",
+			Flags: append(addGroupDurationFlag(addTimeRangeFlags(
+				addPatternFlag(addOutputFlag(addRangeFlags(nil, false))))),
+				&cli.StringFlag{
+					Name:     "asset",
+					Required: true,
+					Usage:    "Asset to replay the transfers of (e.g. EOS@eosio.token, XTZ or XRP)",
+				},
+				&cli.Int64SliceFlag{
+					Name:  "heights",
+					Usage: "Block heights after which to take snapshots, instead of every duration",
+				},
+				&cli.StringSliceFlag{
+					Name:  "accounts",
+					Usage: "Accounts to output, instead of the top accounts by balance",
+				},
+				&cli.IntFlag{
+					Name:  "top",
+					Value: core.DefaultBalancesTop,
+					Usage: "Number of accounts with the highest balances to output, 0 for unlimited",
+				},
+				&cli.StringFlag{
+					Name:  "seed",
+					Usage: "CSV file with the initial balance of the accounts (account,balance)",
+				},
+			),
+			Usage: "Replay transfers in block order and output balance snapshots",
+			Action: makeAction(func(c *cli.Context) error {
+				timeRange, err := getTimeRange(c)
+				if err != nil {
+					return err
+				}
+				duration, err := core.ParseDuration(c.String("duration"))
+				if err != nil {
+					return err
+				}
+				var heights []uint64
+				for _, height := range c.Int64Slice("heights") {
+					if height < 0 {
+						return fmt.Errorf("invalid block height %d", height)
+					}
+					heights = append(heights, uint64(height))
+				}
+				var initial map[string]*big.Rat
+				if c.String("seed") != "" {
+					if initial, err = core.LoadBalances(c.String("seed")); err != nil {
+						return err
+					}
+				}
+				balances, err := processor.ReplayBalances(
+					blockchain, c.String("pattern"),
+					c.Uint64("start"), c.Uint64("end"), timeRange,
+					c.String("asset"), initial, duration, heights,
+					c.StringSlice("accounts"), c.Int("top"))
+				if err != nil {
+					return err
+				}
+				return core.Persist(balances, c.String("output"))
+			}),
+		},
 		{
 			Name:  "bulk-process",
 			Flags: addConfigFlag(addOutputFlag(nil)),
@@ -234,11 +927,15 @@ func addCommonCommands(blockchain core.Blockchain, commands []*cli.Command) []*c
 				}
 				defer file.Close()
 
-				var config processor.BulkConfig
-				if err := json.NewDecoder(file).Decode(&config); err != nil {
+				categories, err := getCategories(c)
+				if err != nil {
+					return err
+				}
+				config := processor.NewBulkConfig(categories)
+				if err := json.NewDecoder(file).Decode(config); err != nil {
 					return err
 				}
-				result, err := processor.RunBulkActions(blockchain, config)
+				result, err := processor.RunBulkActions(blockchain, *config)
 				if err != nil {
 					return err
 				}
@@ -247,38 +944,151 @@ func addCommonCommands(blockchain core.Blockchain, commands []*cli.Command) []*c
 		},
 		{
 			Name:  "export",
-			Flags: addPatternFlag(addOutputFlag(addRangeFlags(nil, false))),
+			Flags: addTimeRangeFlags(addPatternFlag(addOutputFlag(addRangeFlags(nil, false)))),
 			Usage: "Export a subset of the fields to msgpack format for faster processing",
 			Action: makeAction(func(c *cli.Context) error {
+				timeRange, err := getTimeRange(c)
+				if err != nil {
+					return err
+				}
 				return processor.ExportToMsgpack(blockchain, c.String("pattern"),
-					c.Uint64("start"), c.Uint64("end"), c.String("output"))
+					c.Uint64("start"), c.Uint64("end"), timeRange, c.String("output"))
+			}),
+		},
+		{
+			Name: "partition-by-time",
+			Flags: addTimePartitionFlag(addTimeRangeFlags(
+				addPatternFlag(addOutputFlag(addRangeFlags(nil, false))))),
+			Usage: "Rewrite the data into daily or monthly files (e.g. eos-2020-03.jsonl.gz)",
+			Action: makeAction(func(c *cli.Context) error {
+				timeRange, err := getTimeRange(c)
+				if err != nil {
+					return err
+				}
+				partition, err := core.GetTimePartition(c.String("period"))
+				if err != nil {
+					return err
+				}
+				return processor.PartitionByTime(blockchain, c.String("pattern"),
+					c.Uint64("start"), c.Uint64("end"), timeRange, partition, c.String("output"))
+			}),
+		},
+		{
+			Name:  "resolve-time",
+			Flags: addTimeFlag(addOptionalPatternFlag(addRangeFlags(nil, false))),
+			Usage: "Find the first block at or after the given time, using the data or the node if no pattern is given",
+			Action: makeAction(func(c *cli.Context) error {
+				t, err := core.ParseTime(c.String("time"))
+				if err != nil {
+					return err
+				}
+				var blockTime processor.BlockTime
+				if pattern := c.String("pattern"); pattern != "" {
+					blockTime, err = processor.ResolveTime(
+						blockchain, pattern, c.Uint64("start"), c.Uint64("end"), t)
+				} else {
+					fetcher, ok := blockchain.(core.BlockFetcher)
+					if !ok {
+						return fmt.Errorf("blockchain does not support fetching single blocks")
+					}
+					if c.Uint64("end") == 0 {
+						return fmt.Errorf("--end is required when resolving from the node")
+					}
+					blockTime, err = processor.ResolveTimeFromNode(
+						fetcher, c.Uint64("start"), c.Uint64("end"), t)
+				}
+				if err != nil {
+					return err
+				}
+				fmt.Printf("block %d at %s\n", blockTime.Number, blockTime.Time.Format(time.RFC3339))
+				return nil
 			}),
 		},
 	}...)
 }
 
-var eosCommands []*cli.Command = []*cli.Command{
-	{
-		Name:  "export-transfers",
-		Flags: addPatternFlag(addOutputFlag(addRangeFlags(nil, false))),
-		Usage: "Export all the transfers to a CSV file",
-		Action: makeAction(func(c *cli.Context) error {
-			return eos.ExportTransfers(
-				c.String("pattern"),
-				c.Uint64("start"), c.Uint64("end"), c.String("output"))
-		}),
-	},
+func eosCommands(blockchain *eos.EOS) []*cli.Command {
+	return []*cli.Command{
+		{
+			Name: "memo-stats",
+			Flags: append(addGroupDurationFlag(addFilterFlag(
+				addTimeRangeFlags(addPatternFlag(addOutputFlag(addRangeFlags(nil, false)))))),
+				&cli.IntFlag{
+					Name:  "top-receivers",
+					Value: core.DefaultNestedResults,
+					Usage: "Number of receivers with the most transfers to output, 0 for unlimited",
+				},
+				&cli.IntFlag{
+					Name:  "min-deposit-ids",
+					Value: eos.DefaultMinDepositIDs,
+					Usage: "Minimum number of distinct numeric memos to flag a receiver as a likely exchange",
+				},
+			),
+			Usage: "Classify the memos of transfers per receiver over time and detect likely exchanges",
+			Action: makeAction(func(c *cli.Context) error {
+				timeRange, err := getTimeRange(c)
+				if err != nil {
+					return err
+				}
+				filter, err := getFilter(c)
+				if err != nil {
+					return err
+				}
+				duration, err := core.ParseDuration(c.String("duration"))
+				if err != nil {
+					return err
+				}
+				memoStats, err := eos.ComputeMemoStats(
+					blockchain, c.String("pattern"),
+					c.Uint64("start"), c.Uint64("end"), timeRange, filter,
+					duration, c.Int("top-receivers"), c.Int("min-deposit-ids"))
+				if err != nil {
+					return err
+				}
+				return core.Persist(memoStats, c.String("output"))
+			}),
+		},
+	}
 }
 
 func main() {
+	eosBlockchain := eos.New()
 	app := &cli.App{
 		Usage: "Tool to fetch and analyze blockchain transactions",
-		Flags: addCpuProfileFlag(nil),
+		Flags: addCategoriesFlag(addCpuProfileFlag(nil)),
 		Commands: []*cli.Command{
 			{
 				Name:        "eos",
 				Usage:       "Analyze EOS data",
-				Subcommands: addCommonCommands(eos.New(), eosCommands),
+				Subcommands: addCommonCommands(eosBlockchain, eosCommands(eosBlockchain)),
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "traces",
+						Usage: "Fetch and parse blocks from the trace API, including inline actions",
+					},
+					&cli.StringFlag{
+						Name:  "abi-dir",
+						Usage: "Directory of the ABIs used to decode hex-encoded action data, overrides EOS_ABI_DIR",
+					},
+					&cli.BoolFlag{
+						Name:  "fetch-abis",
+						Usage: "Fetch the ABIs missing from the ABI directory from the producer",
+					},
+				},
+				Before: func(c *cli.Context) error {
+					if c.Bool("traces") {
+						eosBlockchain.Traces = true
+					}
+					abiDirectory, fetchABIs := eosBlockchain.ABIDirectory, eosBlockchain.FetchABIs
+					if c.IsSet("abi-dir") {
+						abiDirectory = c.String("abi-dir")
+					}
+					if c.Bool("fetch-abis") {
+						fetchABIs = true
+					}
+					eosBlockchain.SetABIs(abiDirectory, fetchABIs)
+					return nil
+				},
 			},
 			{
 				Name:        "tezos",
@@ -0,0 +1,307 @@
+package core
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+)
+
+type ActiveAccounts struct {
+	senders   map[string]bool
+	receivers map[string]bool
+}
+
+func NewActiveAccounts() *ActiveAccounts {
+	return &ActiveAccounts{
+		senders:   make(map[string]bool),
+		receivers: make(map[string]bool),
+	}
+}
+
+func (a *ActiveAccounts) AddAction(action Action) {
+	if sender := action.Sender(); sender != "" {
+		a.senders[sender] = true
+	}
+	if receiver := action.Receiver(); receiver != "" {
+		a.receivers[receiver] = true
+	}
+}
+
+func (a *ActiveAccounts) SendersCount() int {
+	return len(a.senders)
+}
+
+func (a *ActiveAccounts) ReceiversCount() int {
+	return len(a.receivers)
+}
+
+// ParticipantsCount returns the number of accounts which are either sender or receiver
+func (a *ActiveAccounts) ParticipantsCount() int {
+	count := len(a.senders)
+	for receiver := range a.receivers {
+		if !a.senders[receiver] {
+			count++
+		}
+	}
+	return count
+}
+
+func (a *ActiveAccounts) Merge(other *ActiveAccounts) {
+	for sender := range other.senders {
+		a.senders[sender] = true
+	}
+	for receiver := range other.receivers {
+		a.receivers[receiver] = true
+	}
+}
+
+func (a *ActiveAccounts) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]int{
+		"Senders":      a.SendersCount(),
+		"Receivers":    a.ReceiversCount(),
+		"Participants": a.ParticipantsCount(),
+	})
+}
+
+// TimeGroupedActiveAccounts counts the distinct accounts active in each time bucket
+type TimeGroupedActiveAccounts struct {
+	Accounts map[time.Time]*ActiveAccounts
+	Duration Duration
+}
+
+func NewTimeGroupedActiveAccounts(duration Duration) *TimeGroupedActiveAccounts {
+	return &TimeGroupedActiveAccounts{
+		Accounts: make(map[time.Time]*ActiveAccounts),
+		Duration: duration,
+	}
+}
+
+func (g *TimeGroupedActiveAccounts) AddBlock(block Block) {
+	group := g.Duration.Truncate(block.Time())
+	if _, ok := g.Accounts[group]; !ok {
+		g.Accounts[group] = NewActiveAccounts()
+	}
+	for _, action := range block.ListActions() {
+		g.Accounts[group].AddAction(action)
+	}
+}
+
+func (g *TimeGroupedActiveAccounts) Result() interface{} {
+	return g
+}
+
+type FirstSeen struct {
+	Block  uint64
+	Time   time.Time
+	Action string
+}
+
+// FirstSeenAccounts records the first block in which each account appears
+// as sender or receiver. As blocks are not processed in order, the earliest
+// block seen so far is kept for each account
+type FirstSeenAccounts struct {
+	Accounts   map[string]FirstSeen
+	Duration   Duration
+	exportPath string
+}
+
+func NewFirstSeenAccounts(duration Duration, exportPath string) *FirstSeenAccounts {
+	return &FirstSeenAccounts{
+		Accounts:   make(map[string]FirstSeen),
+		Duration:   duration,
+		exportPath: exportPath,
+	}
+}
+
+func (f *FirstSeenAccounts) see(account string, firstSeen FirstSeen) {
+	if account == "" {
+		return
+	}
+	if current, ok := f.Accounts[account]; !ok || firstSeen.Block < current.Block {
+		f.Accounts[account] = firstSeen
+	}
+}
+
+func (f *FirstSeenAccounts) AddBlock(block Block) {
+	for _, action := range block.ListActions() {
+		firstSeen := FirstSeen{Block: block.Number(), Time: block.Time(), Action: action.Name()}
+		f.see(action.Sender(), firstSeen)
+		f.see(action.Receiver(), firstSeen)
+	}
+}
+
+func (f *FirstSeenAccounts) Merge(other *FirstSeenAccounts) {
+	for account, firstSeen := range other.Accounts {
+		f.see(account, firstSeen)
+	}
+}
+
+// NewAccountsOverTime returns the number of accounts seen for the first time in each time bucket
+func (f *FirstSeenAccounts) NewAccountsOverTime() map[time.Time]uint64 {
+	result := make(map[time.Time]uint64)
+	for _, firstSeen := range f.Accounts {
+		result[f.Duration.Truncate(firstSeen.Time)]++
+	}
+	return result
+}
+
+func (f *FirstSeenAccounts) WriteCSV(writer io.Writer) error {
+	accounts := make([]string, 0, len(f.Accounts))
+	for account := range f.Accounts {
+		accounts = append(accounts, account)
+	}
+	sort.Slice(accounts, func(i, j int) bool {
+		a, b := f.Accounts[accounts[i]], f.Accounts[accounts[j]]
+		if a.Block == b.Block {
+			return accounts[i] < accounts[j]
+		}
+		return a.Block < b.Block
+	})
+
+	csvWriter := csv.NewWriter(writer)
+	if err := csvWriter.Write([]string{"account", "first_block", "first_time", "first_action"}); err != nil {
+		return err
+	}
+	for _, account := range accounts {
+		firstSeen := f.Accounts[account]
+		row := []string{
+			account,
+			strconv.FormatUint(firstSeen.Block, 10),
+			firstSeen.Time.UTC().Format(time.RFC3339),
+			firstSeen.Action,
+		}
+		if err := csvWriter.Write(row); err != nil {
+			return err
+		}
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+// Finalize exports the first-seen data if an export path was given
+func (f *FirstSeenAccounts) Finalize() error {
+	if f.exportPath == "" {
+		return nil
+	}
+	file, err := CreateFile(f.exportPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return f.WriteCSV(file)
+}
+
+func (f *FirstSeenAccounts) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"NewAccounts":   f.NewAccountsOverTime(),
+		"AccountsCount": len(f.Accounts),
+		"Duration":      f.Duration,
+	})
+}
+
+func (f *FirstSeenAccounts) Result() interface{} {
+	return f
+}
+
+type RetentionCohort struct {
+	Cohort time.Time
+	Size   uint64
+	// Active is the number of accounts of the cohort active in each period,
+	// starting with the period of the cohort
+	Active []uint64
+}
+
+// Retention assigns each account to the cohort of its first active period and
+// counts how many accounts of each cohort are active in the following periods
+// An account is active in a period if it is the sender of an action in this period
+type Retention struct {
+	Duration     Duration
+	active       map[time.Time]map[string]bool
+	firstPeriods map[string]time.Time
+}
+
+func NewRetention(duration Duration) *Retention {
+	return &Retention{
+		Duration:     duration,
+		active:       make(map[time.Time]map[string]bool),
+		firstPeriods: make(map[string]time.Time),
+	}
+}
+
+func (r *Retention) addActivity(account string, period time.Time) {
+	if _, ok := r.active[period]; !ok {
+		r.active[period] = make(map[string]bool)
+	}
+	r.active[period][account] = true
+	if first, ok := r.firstPeriods[account]; !ok || period.Before(first) {
+		r.firstPeriods[account] = period
+	}
+}
+
+func (r *Retention) AddBlock(block Block) {
+	period := r.Duration.Truncate(block.Time())
+	for _, action := range block.ListActions() {
+		if sender := action.Sender(); sender != "" {
+			r.addActivity(sender, period)
+		}
+	}
+}
+
+func (r *Retention) Merge(other *Retention) {
+	for period, accounts := range other.active {
+		for account := range accounts {
+			r.addActivity(account, period)
+		}
+	}
+}
+
+// Cohorts returns the cohort x period matrix, sorted by cohort
+func (r *Retention) Cohorts() []*RetentionCohort {
+	if len(r.active) == 0 {
+		return []*RetentionCohort{}
+	}
+	var first, last time.Time
+	for period := range r.active {
+		if first.IsZero() || period.Before(first) {
+			first = period
+		}
+		if period.After(last) {
+			last = period
+		}
+	}
+	indexes := make(map[time.Time]int)
+	var cohorts []*RetentionCohort
+	for period := first; !period.After(last); period = r.Duration.Next(period) {
+		indexes[period] = len(cohorts)
+		cohorts = append(cohorts, &RetentionCohort{Cohort: period})
+	}
+	for i, cohort := range cohorts {
+		cohort.Active = make([]uint64, len(cohorts)-i)
+	}
+
+	for period, accounts := range r.active {
+		periodIndex := indexes[period]
+		for account := range accounts {
+			cohortIndex := indexes[r.firstPeriods[account]]
+			cohorts[cohortIndex].Active[periodIndex-cohortIndex]++
+		}
+	}
+	for _, cohort := range cohorts {
+		cohort.Size = cohort.Active[0]
+	}
+	return cohorts
+}
+
+func (r *Retention) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"Duration": r.Duration,
+		"Cohorts":  r.Cohorts(),
+	})
+}
+
+func (r *Retention) Result() interface{} {
+	return r
+}
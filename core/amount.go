@@ -0,0 +1,108 @@
+package core
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Amount is an exact quantity of an asset
+// Issuer is the contract (EOS) or the issuer (XRP) of the asset
+// and is empty for the native asset of the blockchain
+type Amount struct {
+	Value  *big.Rat
+	Asset  string
+	Issuer string
+}
+
+// NewAmount parses value, which can be a decimal (e.g. 1.2345) or
+// use the scientific notation (e.g. 1e-7), without losing precision
+func NewAmount(value, asset, issuer string) (Amount, error) {
+	parsed, ok := new(big.Rat).SetString(value)
+	if !ok {
+		return Amount{}, fmt.Errorf("invalid amount %s", value)
+	}
+	return Amount{Value: parsed, Asset: asset, Issuer: issuer}, nil
+}
+
+// NewAmountFromUnits returns the amount of units (e.g. drops or mutez)
+// of an asset with the given number of decimals
+func NewAmountFromUnits(units string, decimals int, asset string) (Amount, error) {
+	amount, err := NewAmount(units, asset, "")
+	if err != nil {
+		return amount, err
+	}
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+	amount.Value.Quo(amount.Value, new(big.Rat).SetInt(scale))
+	return amount, nil
+}
+
+// AssetName uniquely identifies the asset, e.g. EOS@eosio.token or XTZ
+func (a Amount) AssetName() string {
+	if a.Issuer == "" {
+		return a.Asset
+	}
+	return a.Asset + "@" + a.Issuer
+}
+
+func (a Amount) String() string {
+	return FormatDecimal(a.Value) + " " + a.AssetName()
+}
+
+// FormatDecimal returns the exact decimal representation of value
+// which must have a finite decimal expansion
+func FormatDecimal(value *big.Rat) string {
+	if value == nil {
+		return "0"
+	}
+	if value.IsInt() {
+		return value.Num().String()
+	}
+	denominator := new(big.Int).Set(value.Denom())
+	two, five, zero := big.NewInt(2), big.NewInt(5), big.NewInt(0)
+	twos, fives := 0, 0
+	for new(big.Int).Mod(denominator, two).Cmp(zero) == 0 {
+		denominator.Quo(denominator, two)
+		twos++
+	}
+	for new(big.Int).Mod(denominator, five).Cmp(zero) == 0 {
+		denominator.Quo(denominator, five)
+		fives++
+	}
+	digits := twos
+	if fives > digits {
+		digits = fives
+	}
+	if denominator.Cmp(big.NewInt(1)) != 0 {
+		// no finite decimal expansion, should not happen for parsed decimals
+		digits = 18
+	}
+	formatted := value.FloatString(digits)
+	if strings.Contains(formatted, ".") {
+		formatted = strings.TrimRight(strings.TrimRight(formatted, "0"), ".")
+	}
+	return formatted
+}
+
+// Transfer is the transfer of an amount from an account to another
+type Transfer struct {
+	From   string
+	To     string
+	Amount Amount
+}
+
+// TransferAction is implemented by actions which can transfer assets
+type TransferAction interface {
+	// Transfer returns the transfer made by the action
+	// and false if the action does not transfer any asset
+	Transfer() (Transfer, bool)
+}
+
+// GetTransfer returns the transfer made by action, if any
+func GetTransfer(action Action) (Transfer, bool) {
+	transferAction, ok := action.(TransferAction)
+	if !ok {
+		return Transfer{}, false
+	}
+	return transferAction.Transfer()
+}
@@ -0,0 +1,53 @@
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewAmount(t *testing.T) {
+	amount, err := NewAmount("1.2345", "EOS", "eosio.token")
+	assert.Nil(t, err)
+	assert.Equal(t, "EOS@eosio.token", amount.AssetName())
+	assert.Equal(t, "1.2345 EOS@eosio.token", amount.String())
+
+	amount, err = NewAmount("1e-7", "USD", "rIssuer")
+	assert.Nil(t, err)
+	assert.Equal(t, "0.0000001", FormatDecimal(amount.Value))
+
+	_, err = NewAmount("abc", "EOS", "")
+	assert.NotNil(t, err)
+
+	amount, err = NewAmountFromUnits("1000001", 6, "XRP")
+	assert.Nil(t, err)
+	assert.Equal(t, "1.000001 XRP", amount.String())
+}
+
+func TestFormatDecimal(t *testing.T) {
+	sum := new(big.Rat)
+	for _, value := range []string{"0.1", "0.2"} {
+		amount, _ := NewAmount(value, "EOS", "")
+		sum.Add(sum, amount.Value)
+	}
+	assert.Equal(t, "0.3", FormatDecimal(sum))
+	assert.Equal(t, "42", FormatDecimal(big.NewRat(42, 1)))
+	assert.Equal(t, "-0.125", FormatDecimal(big.NewRat(-1, 8)))
+	assert.Equal(t, "0", FormatDecimal(nil))
+}
+
+type testTransferAction struct {
+	testAction
+	transfer Transfer
+}
+
+func (a testTransferAction) Transfer() (Transfer, bool) { return a.transfer, true }
+
+func newTestTransfer(from, to, value, asset string) Action {
+	amount, _ := NewAmount(value, asset, "eosio.token")
+	return testTransferAction{
+		testAction{"transfer", from, "eosio.token"},
+		Transfer{From: from, To: to, Amount: amount},
+	}
+}
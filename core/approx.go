@@ -0,0 +1,139 @@
+package core
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// ApproxUniqueActions estimates the number of distinct keys of the actions
+// using a constant amount of memory
+type ApproxUniqueActions struct {
+	GroupedBy    ActionProperties
+	ActionsCount uint64
+	sketch       *HyperLogLog
+}
+
+func NewApproxUniqueActions(by ActionProperties, relativeError float64) (*ApproxUniqueActions, error) {
+	sketch, err := NewHyperLogLogWithError(relativeError)
+	if err != nil {
+		return nil, err
+	}
+	return &ApproxUniqueActions{
+		GroupedBy: by,
+		sketch:    sketch,
+	}, nil
+}
+
+func (a *ApproxUniqueActions) AddBlock(block Block) {
+	for _, action := range block.ListActions() {
+		a.ActionsCount++
+		if keys := a.GroupedBy.NonEmptyKeys(block, action); keys != nil {
+			a.sketch.Add(groupKey(keys))
+		}
+	}
+}
+
+func (a *ApproxUniqueActions) UniqueCount() uint64 {
+	return a.sketch.Count()
+}
+
+func (a *ApproxUniqueActions) Merge(other *ApproxUniqueActions) error {
+	if err := a.sketch.Merge(other.sketch); err != nil {
+		return err
+	}
+	a.ActionsCount += other.ActionsCount
+	return nil
+}
+
+func (a *ApproxUniqueActions) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"GroupedBy":     a.GroupedBy,
+		"ActionsCount":  a.ActionsCount,
+		"UniqueCount":   a.UniqueCount(),
+		"RelativeError": a.sketch.RelativeError(),
+	})
+}
+
+func (a *ApproxUniqueActions) Result() interface{} {
+	return a
+}
+
+// HeavyHitters approximates the K most frequent keys of the actions
+// using Space-Saving to track the candidates and a count-min sketch to
+// tighten their estimated counts
+type HeavyHitters struct {
+	GroupedBy    ActionProperties
+	ActionsCount uint64
+	summary      *SpaceSaving
+	sketch       *CountMinSketch
+	epsilon      float64
+}
+
+func NewHeavyHitters(by ActionProperties, k int, epsilon, delta float64) (*HeavyHitters, error) {
+	summary, err := NewSpaceSaving(k)
+	if err != nil {
+		return nil, err
+	}
+	sketch, err := NewCountMinSketch(epsilon, delta)
+	if err != nil {
+		return nil, err
+	}
+	return &HeavyHitters{
+		GroupedBy: by,
+		summary:   summary,
+		sketch:    sketch,
+		epsilon:   epsilon,
+	}, nil
+}
+
+func (h *HeavyHitters) AddBlock(block Block) {
+	for _, action := range block.ListActions() {
+		h.ActionsCount++
+		if keys := h.GroupedBy.NonEmptyKeys(block, action); keys != nil {
+			key := groupKey(keys)
+			h.summary.Add(key, 1)
+			h.sketch.Add(key, 1)
+		}
+	}
+}
+
+// Top returns the estimated most frequent keys, sorted by decreasing count,
+// with composite keys joined by commas
+// Count is an upper bound of the actual count and Count - Error a lower bound
+func (h *HeavyHitters) Top() []*SpaceSavingCounter {
+	counters := h.summary.Top()
+	for i, counter := range counters {
+		name := strings.Replace(counter.Name, "\x00", ",", -1)
+		lowerBound := counter.Count - counter.Error
+		if estimate := h.sketch.Estimate(counter.Name); estimate < counter.Count {
+			counters[i] = &SpaceSavingCounter{Name: name, Count: estimate, Error: estimate - lowerBound}
+		} else {
+			counters[i] = &SpaceSavingCounter{Name: name, Count: counter.Count, Error: counter.Error}
+		}
+	}
+	sortSpaceSavingCounters(counters)
+	return counters
+}
+
+func (h *HeavyHitters) Merge(other *HeavyHitters) error {
+	if err := h.sketch.Merge(other.sketch); err != nil {
+		return err
+	}
+	h.summary.Merge(other.summary)
+	h.ActionsCount += other.ActionsCount
+	return nil
+}
+
+func (h *HeavyHitters) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"GroupedBy":    h.GroupedBy,
+		"ActionsCount": h.ActionsCount,
+		"Actions":      h.Top(),
+		"K":            h.summary.k,
+		"MaxError":     uint64(h.epsilon * float64(h.sketch.TotalCount)),
+	})
+}
+
+func (h *HeavyHitters) Result() interface{} {
+	return h
+}
@@ -0,0 +1,33 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// commaTestActions have keys which would collide if joined with commas
+var commaTestActions = []Action{
+	testAction{"transfer", "a,b", "c"},
+	testAction{"transfer", "a", "b,c"},
+	testAction{"transfer", "a", "b,c"},
+}
+
+func TestApproxUniqueActionsCompositeKeys(t *testing.T) {
+	unique, err := NewApproxUniqueActions(ActionProperties{ActionSender, ActionReceiver}, 0.01)
+	assert.Nil(t, err)
+	unique.AddBlock(newTestBlock(commaTestActions...))
+	assert.Equal(t, uint64(3), unique.ActionsCount)
+	assert.Equal(t, uint64(2), unique.UniqueCount())
+}
+
+func TestHeavyHittersCompositeKeys(t *testing.T) {
+	heavyHitters, err := NewHeavyHitters(ActionProperties{ActionSender, ActionReceiver}, 5, 0.01, 0.01)
+	assert.Nil(t, err)
+	heavyHitters.AddBlock(newTestBlock(commaTestActions...))
+	top := heavyHitters.Top()
+	if assert.Len(t, top, 2) {
+		assert.Equal(t, &SpaceSavingCounter{Name: "a,b,c", Count: 2}, top[0])
+		assert.Equal(t, &SpaceSavingCounter{Name: "a,b,c", Count: 1}, top[1])
+	}
+}
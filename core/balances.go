@@ -0,0 +1,228 @@
+package core
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"time"
+)
+
+const DefaultBalancesTop = 100
+
+// LoadBalances reads the initial balances of the accounts from
+// a CSV file with account and balance columns, e.g. a genesis snapshot
+func LoadBalances(filename string) (map[string]*big.Rat, error) {
+	reader, err := OpenFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	csvReader := csv.NewReader(reader)
+	balances := make(map[string]*big.Rat)
+	for line := 1; ; line++ {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			return balances, nil
+		} else if err != nil {
+			return nil, err
+		}
+		if len(record) != 2 {
+			return nil, fmt.Errorf("line %d: expected account and balance", line)
+		}
+		balance, ok := new(big.Rat).SetString(record[1])
+		if !ok {
+			if line == 1 {
+				// header
+				continue
+			}
+			return nil, fmt.Errorf("line %d: invalid balance %s", line, record[1])
+		}
+		balances[record[0]] = balance
+	}
+}
+
+type AccountBalance struct {
+	Account string
+	Balance string
+}
+
+// BalanceSnapshot contains the balances after all the blocks up to Block
+// or, for snapshots taken over time, before Time
+type BalanceSnapshot struct {
+	Time          time.Time `json:",omitempty"`
+	Block         uint64    `json:",omitempty"`
+	AccountsCount int
+	Balances      []AccountBalance
+}
+
+// Balances reconstructs the balances of the accounts for an asset by summing
+// the transfers. As additions commute, blocks can be processed in any order
+// and the changes are only accumulated in block order when taking snapshots
+type Balances struct {
+	Asset         string
+	Duration      Duration
+	Heights       []uint64
+	accounts      []string
+	top           int
+	initial       map[string]*big.Rat
+	timeChanges   map[time.Time]map[string]*big.Rat
+	heightChanges []map[string]*big.Rat
+}
+
+// NewBalances replays the transfers of asset (e.g. EOS@eosio.token, XTZ or XRP)
+// starting from the initial balances, which can be nil
+func NewBalances(asset string, initial map[string]*big.Rat) *Balances {
+	if initial == nil {
+		initial = make(map[string]*big.Rat)
+	}
+	return &Balances{
+		Asset:       asset,
+		Duration:    NewDuration(24 * time.Hour),
+		top:         DefaultBalancesTop,
+		initial:     initial,
+		timeChanges: make(map[time.Time]map[string]*big.Rat),
+	}
+}
+
+// SnapshotEvery takes a snapshot at the end of every period
+func (b *Balances) SnapshotEvery(duration Duration) *Balances {
+	b.Duration, b.Heights = duration, nil
+	return b
+}
+
+// SnapshotAt takes a snapshot after each of the given block heights
+func (b *Balances) SnapshotAt(heights []uint64) *Balances {
+	b.Heights = append([]uint64{}, heights...)
+	sort.Slice(b.Heights, func(i, j int) bool { return b.Heights[i] < b.Heights[j] })
+	b.heightChanges = make([]map[string]*big.Rat, len(b.Heights))
+	for i := range b.heightChanges {
+		b.heightChanges[i] = make(map[string]*big.Rat)
+	}
+	return b
+}
+
+// SetAccounts selects the accounts output in the snapshots
+// If no accounts are given, the top accounts by balance are output
+func (b *Balances) SetAccounts(accounts []string, top int) *Balances {
+	b.accounts, b.top = accounts, top
+	return b
+}
+
+func (b *Balances) changes(block Block) map[string]*big.Rat {
+	if len(b.Heights) == 0 {
+		group := b.Duration.Truncate(block.Time())
+		if _, ok := b.timeChanges[group]; !ok {
+			b.timeChanges[group] = make(map[string]*big.Rat)
+		}
+		return b.timeChanges[group]
+	}
+	i := sort.Search(len(b.Heights), func(i int) bool {
+		return b.Heights[i] >= block.Number()
+	})
+	if i == len(b.Heights) {
+		return nil
+	}
+	return b.heightChanges[i]
+}
+
+func addBalance(balances map[string]*big.Rat, account string, value *big.Rat) {
+	if _, ok := balances[account]; !ok {
+		balances[account] = new(big.Rat)
+	}
+	balances[account].Add(balances[account], value)
+}
+
+func (b *Balances) AddBlock(block Block) {
+	var changes map[string]*big.Rat
+	for _, action := range block.ListActions() {
+		transfer, ok := GetTransfer(action)
+		if !ok || transfer.Amount.AssetName() != b.Asset || transfer.From == transfer.To {
+			continue
+		}
+		if changes == nil {
+			if changes = b.changes(block); changes == nil {
+				return
+			}
+		}
+		addBalance(changes, transfer.From, new(big.Rat).Neg(transfer.Amount.Value))
+		addBalance(changes, transfer.To, transfer.Amount.Value)
+	}
+}
+
+func (b *Balances) snapshot(balances map[string]*big.Rat) BalanceSnapshot {
+	snapshot := BalanceSnapshot{AccountsCount: len(balances)}
+	if len(b.accounts) > 0 {
+		for _, account := range b.accounts {
+			snapshot.Balances = append(snapshot.Balances,
+				AccountBalance{Account: account, Balance: FormatDecimal(balances[account])})
+		}
+		return snapshot
+	}
+	accounts := make([]string, 0, len(balances))
+	for account := range balances {
+		accounts = append(accounts, account)
+	}
+	sort.Slice(accounts, func(i, j int) bool {
+		comparison := balances[accounts[i]].Cmp(balances[accounts[j]])
+		if comparison == 0 {
+			return accounts[i] < accounts[j]
+		}
+		return comparison > 0
+	})
+	if b.top > 0 && len(accounts) > b.top {
+		accounts = accounts[:b.top]
+	}
+	for _, account := range accounts {
+		snapshot.Balances = append(snapshot.Balances,
+			AccountBalance{Account: account, Balance: FormatDecimal(balances[account])})
+	}
+	return snapshot
+}
+
+// Snapshots returns the balances after each period or block height
+func (b *Balances) Snapshots() []BalanceSnapshot {
+	balances := make(map[string]*big.Rat)
+	for account, balance := range b.initial {
+		addBalance(balances, account, balance)
+	}
+	var snapshots []BalanceSnapshot
+	if len(b.Heights) > 0 {
+		for i, height := range b.Heights {
+			for account, change := range b.heightChanges[i] {
+				addBalance(balances, account, change)
+			}
+			snapshot := b.snapshot(balances)
+			snapshot.Block = height
+			snapshots = append(snapshots, snapshot)
+		}
+		return snapshots
+	}
+	groups := make([]time.Time, 0, len(b.timeChanges))
+	for group := range b.timeChanges {
+		groups = append(groups, group)
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Before(groups[j]) })
+	for _, group := range groups {
+		for account, change := range b.timeChanges[group] {
+			addBalance(balances, account, change)
+		}
+		snapshot := b.snapshot(balances)
+		snapshot.Time = b.Duration.Next(group)
+		snapshots = append(snapshots, snapshot)
+	}
+	return snapshots
+}
+
+func (b *Balances) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"Asset":     b.Asset,
+		"Snapshots": b.Snapshots(),
+	})
+}
+
+func (b *Balances) Result() interface{} {
+	return b
+}
@@ -0,0 +1,74 @@
+package core
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBalancesOverTime(t *testing.T) {
+	start := testBlockTime
+	initial := map[string]*big.Rat{"alice": big.NewRat(10, 1)}
+	balances := NewBalances("EOS@eosio.token", initial).SnapshotEvery(NewDuration(time.Hour))
+	// blocks are added out of order
+	balances.AddBlock(newTestBlock(
+		newTestTransfer("bob", "carol", "1.5", "EOS")).at(2, start.Add(time.Hour)))
+	balances.AddBlock(newTestBlock(
+		newTestTransfer("alice", "bob", "2.5", "EOS"),
+		newTestTransfer("alice", "bob", "100", "BET"),
+		testAction{"bet", "alice", "betdicegroup"}).at(1, start))
+
+	snapshots := balances.Snapshots()
+	if assert.Len(t, snapshots, 2) {
+		assert.Equal(t, start.Add(time.Hour), snapshots[0].Time)
+		assert.Equal(t, 2, snapshots[0].AccountsCount)
+		assert.Equal(t, []AccountBalance{{"alice", "7.5"}, {"bob", "2.5"}}, snapshots[0].Balances)
+		assert.Equal(t, 3, snapshots[1].AccountsCount)
+		assert.Equal(t, []AccountBalance{{"alice", "7.5"}, {"carol", "1.5"}, {"bob", "1"}},
+			snapshots[1].Balances)
+	}
+
+	balances.SetAccounts([]string{"carol", "dave"}, 0)
+	snapshots = balances.Snapshots()
+	assert.Equal(t, []AccountBalance{{"carol", "0"}, {"dave", "0"}}, snapshots[0].Balances)
+}
+
+func TestBalancesAtHeights(t *testing.T) {
+	start := testBlockTime
+	balances := NewBalances("EOS@eosio.token", nil).SnapshotAt([]uint64{20, 10}).SetAccounts(nil, 1)
+	balances.AddBlock(newTestBlock(newTestTransfer("alice", "bob", "1", "EOS")).at(10, start))
+	balances.AddBlock(newTestBlock(newTestTransfer("alice", "bob", "2", "EOS")).at(15, start))
+	balances.AddBlock(newTestBlock(newTestTransfer("alice", "bob", "4", "EOS")).at(30, start))
+
+	snapshots := balances.Snapshots()
+	if assert.Len(t, snapshots, 2) {
+		assert.Equal(t, uint64(10), snapshots[0].Block)
+		assert.Equal(t, []AccountBalance{{"bob", "1"}}, snapshots[0].Balances)
+		assert.Equal(t, uint64(20), snapshots[1].Block)
+		assert.Equal(t, []AccountBalance{{"bob", "3"}}, snapshots[1].Balances)
+	}
+}
+
+func TestLoadBalances(t *testing.T) {
+	dir, err := ioutil.TempDir("", "balances")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "genesis.csv")
+	content := "account,balance\nalice,10.5\nbob,1e-4\n"
+	assert.Nil(t, ioutil.WriteFile(filename, []byte(content), 0644))
+	balances, err := LoadBalances(filename)
+	assert.Nil(t, err)
+	assert.Len(t, balances, 2)
+	assert.Equal(t, "10.5", FormatDecimal(balances["alice"]))
+	assert.Equal(t, "0.0001", FormatDecimal(balances["bob"]))
+
+	assert.Nil(t, ioutil.WriteFile(filename, []byte("alice,1\nbob,abc\n"), 0644))
+	_, err = LoadBalances(filename)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,210 @@
+package core
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+	"time"
+)
+
+// DefaultStallFactor is the number of times the median interval
+// after which the interval between two blocks is considered a stall
+const DefaultStallFactor = 10.0
+
+type blockSummary struct {
+	number            uint64
+	time              time.Time
+	transactionsCount int
+	actionsCount      int
+}
+
+// percentile returns the nearest-rank percentile of sorted values
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// IntervalDistribution summarizes the intervals between blocks, in seconds
+type IntervalDistribution struct {
+	Count int
+	Min   float64
+	Mean  float64
+	P50   float64
+	P90   float64
+	P99   float64
+	Max   float64
+}
+
+func NewIntervalDistribution(intervals []float64) IntervalDistribution {
+	if len(intervals) == 0 {
+		return IntervalDistribution{}
+	}
+	sorted := make([]float64, len(intervals))
+	copy(sorted, intervals)
+	sort.Float64s(sorted)
+	sum := 0.0
+	for _, interval := range sorted {
+		sum += interval
+	}
+	return IntervalDistribution{
+		Count: len(sorted),
+		Min:   sorted[0],
+		Mean:  sum / float64(len(sorted)),
+		P50:   percentile(sorted, 50),
+		P90:   percentile(sorted, 90),
+		P99:   percentile(sorted, 99),
+		Max:   sorted[len(sorted)-1],
+	}
+}
+
+// BlockTimesStats are the block interval and throughput statistics of a period
+// Throughputs are computed over the sum of the intervals ending in the period
+type BlockTimesStats struct {
+	BlocksCount            int
+	EmptyBlocksCount       int
+	EmptyBlocksRatio       float64
+	TransactionsCount      int
+	ActionsCount           int
+	TransactionsPerSecond  float64
+	ActionsPerSecond       float64
+	Intervals              IntervalDistribution
+	StallsCount            int
+	intervals              []float64
+	throughputTransactions int
+	throughputActions      int
+}
+
+func (s *BlockTimesStats) addBlock(block blockSummary) {
+	s.BlocksCount++
+	if block.transactionsCount == 0 {
+		s.EmptyBlocksCount++
+	}
+	s.TransactionsCount += block.transactionsCount
+	s.ActionsCount += block.actionsCount
+}
+
+func (s *BlockTimesStats) addInterval(block blockSummary, interval float64) {
+	s.intervals = append(s.intervals, interval)
+	s.throughputTransactions += block.transactionsCount
+	s.throughputActions += block.actionsCount
+}
+
+func (s *BlockTimesStats) compute() {
+	if s.BlocksCount > 0 {
+		s.EmptyBlocksRatio = float64(s.EmptyBlocksCount) / float64(s.BlocksCount)
+	}
+	s.Intervals = NewIntervalDistribution(s.intervals)
+	elapsed := s.Intervals.Mean * float64(s.Intervals.Count)
+	if elapsed > 0 {
+		s.TransactionsPerSecond = float64(s.throughputTransactions) / elapsed
+		s.ActionsPerSecond = float64(s.throughputActions) / elapsed
+	}
+}
+
+// Stall is an interval between two consecutive blocks much longer than usual
+type Stall struct {
+	FromBlock uint64
+	ToBlock   uint64
+	From      time.Time
+	Seconds   float64
+}
+
+// BlockTimes computes the intervals between consecutive blocks and the throughput
+// over time. Blocks are collected so that they can be processed in any order
+type BlockTimes struct {
+	Duration    Duration
+	StallFactor float64
+	blocks      map[uint64]blockSummary
+}
+
+func NewBlockTimes(duration Duration, stallFactor float64) *BlockTimes {
+	return &BlockTimes{
+		Duration:    duration,
+		StallFactor: stallFactor,
+		blocks:      make(map[uint64]blockSummary),
+	}
+}
+
+func (b *BlockTimes) AddBlock(block Block) {
+	b.blocks[block.Number()] = blockSummary{
+		number:            block.Number(),
+		time:              block.Time(),
+		transactionsCount: block.TransactionsCount(),
+		actionsCount:      len(block.ListActions()),
+	}
+}
+
+// Stats returns the statistics of each period, of the whole range and the detected stalls
+// Intervals are only computed between blocks with consecutive numbers
+func (b *BlockTimes) Stats() (map[time.Time]*BlockTimesStats, *BlockTimesStats, []Stall) {
+	blocks := make([]blockSummary, 0, len(b.blocks))
+	for _, block := range b.blocks {
+		blocks = append(blocks, block)
+	}
+	sort.Slice(blocks, func(i, j int) bool {
+		return blocks[i].number < blocks[j].number
+	})
+
+	stats := make(map[time.Time]*BlockTimesStats)
+	total := &BlockTimesStats{}
+	groups := make([]*BlockTimesStats, len(blocks))
+	for i, block := range blocks {
+		group := b.Duration.Truncate(block.time)
+		if _, ok := stats[group]; !ok {
+			stats[group] = &BlockTimesStats{}
+		}
+		groups[i] = stats[group]
+		groups[i].addBlock(block)
+		total.addBlock(block)
+		if i > 0 && blocks[i-1].number+1 == block.number {
+			interval := block.time.Sub(blocks[i-1].time).Seconds()
+			groups[i].addInterval(block, interval)
+			total.addInterval(block, interval)
+		}
+	}
+	total.compute()
+
+	var stalls []Stall
+	threshold := total.Intervals.P50 * b.StallFactor
+	for i, block := range blocks {
+		if i == 0 || blocks[i-1].number+1 != block.number {
+			continue
+		}
+		interval := block.time.Sub(blocks[i-1].time).Seconds()
+		if threshold > 0 && interval > threshold {
+			stalls = append(stalls, Stall{
+				FromBlock: blocks[i-1].number,
+				ToBlock:   block.number,
+				From:      blocks[i-1].time,
+				Seconds:   interval,
+			})
+			groups[i].StallsCount++
+			total.StallsCount++
+		}
+	}
+	for _, s := range stats {
+		s.compute()
+	}
+	return stats, total, stalls
+}
+
+func (b *BlockTimes) MarshalJSON() ([]byte, error) {
+	stats, total, stalls := b.Stats()
+	return json.Marshal(map[string]interface{}{
+		"Duration":    b.Duration,
+		"StallFactor": b.StallFactor,
+		"BlockTimes":  stats,
+		"Total":       total,
+		"Stalls":      stalls,
+	})
+}
+
+func (b *BlockTimes) Result() interface{} {
+	return b
+}
@@ -0,0 +1,58 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewIntervalDistribution(t *testing.T) {
+	distribution := NewIntervalDistribution([]float64{4, 1, 3, 2})
+	assert.Equal(t, IntervalDistribution{
+		Count: 4, Min: 1, Mean: 2.5, P50: 2, P90: 4, P99: 4, Max: 4,
+	}, distribution)
+	assert.Equal(t, IntervalDistribution{}, NewIntervalDistribution(nil))
+}
+
+func TestBlockTimes(t *testing.T) {
+	start := testBlockTime
+	blockTimes := NewBlockTimes(NewDuration(time.Minute), 10)
+	offsets := []time.Duration{0, 2, 4, 6, 8, 70, 72}
+	for i, offset := range offsets {
+		block := newTestBlock()
+		block.number = uint64(i + 1)
+		block.time = start.Add(offset * time.Second)
+		if i%2 == 1 {
+			block.actions = nil
+		}
+		blockTimes.AddBlock(block)
+	}
+	// block 8 is not consecutive and should not create an interval
+	block := newTestBlock()
+	block.number = 10
+	block.time = start.Add(74 * time.Second)
+	blockTimes.AddBlock(block)
+
+	stats, total, stalls := blockTimes.Stats()
+	assert.Len(t, stats, 2)
+	assert.Equal(t, 8, total.BlocksCount)
+	assert.Equal(t, 3, total.EmptyBlocksCount)
+	assert.Equal(t, 6, total.Intervals.Count)
+	assert.Equal(t, 2.0, total.Intervals.P50)
+	assert.Equal(t, 62.0, total.Intervals.Max)
+
+	assert.Equal(t, []Stall{{FromBlock: 5, ToBlock: 6, From: start.Add(8 * time.Second), Seconds: 62}}, stalls)
+	assert.Equal(t, 1, total.StallsCount)
+
+	first := stats[start]
+	assert.Equal(t, 5, first.BlocksCount)
+	assert.Equal(t, 4, first.Intervals.Count)
+	// blocks 3 and 5 have 3 actions each over 8 seconds
+	assert.InDelta(t, 6.0/8.0, first.ActionsPerSecond, 1e-9)
+	assert.InDelta(t, 0.4, first.EmptyBlocksRatio, 1e-9)
+
+	second := stats[start.Add(time.Minute)]
+	assert.Equal(t, 1, second.StallsCount)
+	assert.Equal(t, 2, second.Intervals.Count)
+}
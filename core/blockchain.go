@@ -10,15 +10,76 @@ type Blockchain interface {
 	EmptyBlock() Block
 }
 
+// ConfigurableBlockchain is implemented by blockchains with options changing
+// how blocks are fetched or parsed, which are saved with bulk processing results
+type ConfigurableBlockchain interface {
+	Options() interface{}
+}
+
+// BlockFetcher is implemented by blockchains able to fetch a single block from a node
+type BlockFetcher interface {
+	FetchBlock(number uint64) (Block, error)
+}
+
 type Block interface {
 	Number() uint64
 	TransactionsCount() int
 	Time() time.Time
 	ListActions() []Action
+	// ListTransactions returns the transactions of the block with their actions
+	ListTransactions() []Transaction
+	// Producer returns the account which produced the block
+	// or an empty string if it is not known
+	Producer() string
+}
+
+// SlotBlock is implemented by blocks produced in fixed time slots,
+// which allows to detect the slots missed between two consecutive blocks
+type SlotBlock interface {
+	SlotDuration() time.Duration
+}
+
+// PriorityBlock is implemented by blocks produced according to a priority list,
+// where the priority is the number of producers which missed the slot before the block
+type PriorityBlock interface {
+	Priority() int
+}
+
+// Transaction groups the actions executed atomically, e.g. an EOS transaction,
+// a Tezos operation or an XRP transaction
+// Signer is the account which signed the transaction, if known
+type Transaction struct {
+	ID      string
+	Index   int
+	Signer  string
+	Actions []Action
 }
 
 type Action interface {
 	Sender() string
 	Receiver() string
 	Name() string
+	// Status returns whether the transaction containing the action succeeded
+	Status() Status
+}
+
+const dataFieldPrefix = "data."
+
+// DataAction is implemented by actions whose data can be decoded,
+// such as EOS actions, to group and filter actions by data fields
+type DataAction interface {
+	// DataField returns the value of the field, nested fields being
+	// separated by dots (e.g. quotes.0.pair), and false if there is no such field
+	DataField(path string) (string, bool)
+}
+
+// GetDataField returns the value of the data field of action
+// or an empty string if the action has no such field
+func GetDataField(action Action, path string) string {
+	dataAction, ok := action.(DataAction)
+	if !ok {
+		return ""
+	}
+	value, _ := dataAction.DataField(path)
+	return value
 }
@@ -0,0 +1,98 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+)
+
+// DefaultCategory is used for unmapped values when the file
+// does not give a default category
+const DefaultCategory = "others"
+
+type Category struct {
+	Name  string
+	Color string `json:",omitempty"`
+}
+
+// Categories maps values of an action property, usually accounts, to categories
+// Files follow the format of eos-categories.json, with "categories" and
+// "mapping" keys and an optional "default"
+type Categories struct {
+	Categories []Category
+	Mapping    map[string]string
+	Default    string
+}
+
+func NewCategories() *Categories {
+	return &Categories{Mapping: make(map[string]string), Default: DefaultCategory}
+}
+
+// LoadCategories loads and merges the given files, later files taking precedence
+func LoadCategories(filenames ...string) (*Categories, error) {
+	categories := NewCategories()
+	for _, filename := range filenames {
+		if err := categories.load(filename); err != nil {
+			return nil, fmt.Errorf("%s: %s", filename, err.Error())
+		}
+	}
+	return categories, nil
+}
+
+func (c *Categories) load(filename string) error {
+	reader, err := OpenFile(filename)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	content, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+	var categories Categories
+	if err := json.Unmarshal(content, &categories); err != nil {
+		return err
+	}
+	if categories.Mapping == nil {
+		return fmt.Errorf("no category mapping, expected the format of eos-categories.json")
+	}
+	c.merge(&categories)
+	return nil
+}
+
+func (c *Categories) merge(other *Categories) {
+	known := make(map[string]bool)
+	for _, category := range c.Categories {
+		known[category.Name] = true
+	}
+	add := func(category Category) {
+		if !known[category.Name] {
+			known[category.Name] = true
+			c.Categories = append(c.Categories, category)
+		}
+	}
+	for _, category := range other.Categories {
+		add(category)
+	}
+	var mapped []string
+	for value, category := range other.Mapping {
+		c.Mapping[value] = category
+		mapped = append(mapped, category)
+	}
+	sort.Strings(mapped)
+	for _, category := range mapped {
+		add(Category{Name: category})
+	}
+	if other.Default != "" {
+		c.Default = other.Default
+	}
+}
+
+// Get returns the category of value or the default category
+func (c *Categories) Get(value string) string {
+	if category, ok := c.Mapping[value]; ok {
+		return category
+	}
+	return c.Default
+}
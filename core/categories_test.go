@@ -0,0 +1,92 @@
+package core
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadCategories(t *testing.T) {
+	categories, err := LoadCategories(GetFixture(CategoriesFilename))
+	assert.Nil(t, err)
+	assert.Equal(t, []Category{{"tokens", "blue"}, {"betting", "gray"}, {"others", "brown"}},
+		categories.Categories)
+	assert.Equal(t, "tokens", categories.Get("eosio.token"))
+	assert.Equal(t, "betting", categories.Get("betdicegroup"))
+	assert.Equal(t, DefaultCategory, categories.Get("alice"))
+
+	categories, err = LoadCategories(GetFixture(CategoriesFilename), GetFixture(CategoriesOverrideFilename))
+	assert.Nil(t, err)
+	assert.Equal(t, "dapp", categories.Get("betdicegroup"))
+	assert.Equal(t, "user", categories.Get("alice"))
+	assert.Equal(t, "tokens", categories.Get("eosio.token"))
+	assert.Len(t, categories.Categories, 5)
+
+	_, err = LoadCategories(GetFixture("missing.json"))
+	assert.NotNil(t, err)
+}
+
+func TestLoadCategoriesWithoutMapping(t *testing.T) {
+	dir, err := ioutil.TempDir("", "categories")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	// account descriptions, as in eos-accounts.json, are not categories
+	accounts := filepath.Join(dir, "accounts.json")
+	assert.Nil(t, ioutil.WriteFile(accounts, []byte(`{"eosio.token": "Token contract"}`), 0644))
+	_, err = LoadCategories(accounts)
+	assert.NotNil(t, err)
+}
+
+func TestCategoryProperty(t *testing.T) {
+	_, err := GetActionProperty("category", nil)
+	assert.NotNil(t, err)
+
+	categories, err := LoadCategories(GetFixture(CategoriesFilename))
+	assert.Nil(t, err)
+
+	block := newTestBlock()
+	property, err := GetActionProperty("category", categories)
+	assert.Nil(t, err)
+	assert.Equal(t, "category", property.String())
+	assert.Equal(t, "tokens", property.Get(block, block.actions[1]))
+	assert.Equal(t, "betting", property.Get(block, block.actions[2]))
+
+	property, err = GetActionProperty("category.sender", categories)
+	assert.Nil(t, err)
+	assert.Equal(t, "category.sender", property.String())
+	assert.Equal(t, "others", property.Get(block, block.actions[0]))
+
+	_, err = GetActionProperty("category.category", categories)
+	assert.NotNil(t, err)
+	_, err = GetActionProperty("category.foo", categories)
+	assert.NotNil(t, err)
+
+	grouped := NewGroupedActions(ActionProperties{ActionCategory(ActionReceiver, categories)}, false)
+	grouped.AddBlock(block)
+	assert.Equal(t, uint64(2), grouped.GetCount("tokens"))
+	assert.Equal(t, uint64(1), grouped.GetCount("betting"))
+}
+
+func TestActionPropertiesWithCategories(t *testing.T) {
+	var properties ActionProperties
+	assert.Nil(t, json.Unmarshal([]byte(`["category.sender", "name"]`), &properties))
+	_, err := properties.WithCategories(nil)
+	assert.NotNil(t, err)
+
+	categories, err := LoadCategories(GetFixture(CategoriesOverrideFilename))
+	assert.Nil(t, err)
+	bound, err := properties.WithCategories(categories)
+	assert.Nil(t, err)
+	assert.Equal(t, "category.sender,name", bound.String())
+	block := newTestBlock()
+	assert.Equal(t, []string{"user", "transfer"}, bound.Keys(block, block.actions[0]))
+
+	// properties without categories are unchanged
+	bound, err = ActionProperties{ActionSender}.WithCategories(nil)
+	assert.Nil(t, err)
+	assert.Equal(t, ActionProperties{ActionSender}, bound)
+}
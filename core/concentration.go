@@ -0,0 +1,135 @@
+package core
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+)
+
+var (
+	DefaultConcentrationProperties = ActionProperties{ActionName, ActionSender, ActionReceiver}
+	DefaultConcentrationTopN       = []int{1, 10, 100}
+)
+
+// ConcentrationMetrics measures how concentrated the actions are on a few keys
+// HHI is the sum of the squared shares, between 1 / UniqueCount and 1
+// NakamotoCoefficient is the minimum number of keys accounting for more than half of the actions
+type ConcentrationMetrics struct {
+	TotalCount          uint64
+	UniqueCount         int
+	Gini                float64
+	HHI                 float64
+	TopShares           map[int]float64
+	NakamotoCoefficient int
+}
+
+// ComputeConcentration returns the concentration metrics of counts,
+// computing the share of the top n keys for each n of topN
+func ComputeConcentration(counts []uint64, topN []int) ConcentrationMetrics {
+	sorted := make([]uint64, len(counts))
+	copy(sorted, counts)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] > sorted[j] })
+
+	metrics := ConcentrationMetrics{
+		UniqueCount: len(sorted),
+		TopShares:   make(map[int]float64),
+	}
+	for _, count := range sorted {
+		metrics.TotalCount += count
+	}
+	if metrics.TotalCount == 0 {
+		return metrics
+	}
+	total := float64(metrics.TotalCount)
+	n := float64(len(sorted))
+
+	var cumulative uint64
+	var weightedSum float64
+	for i, count := range sorted {
+		share := float64(count) / total
+		metrics.HHI += share * share
+		cumulative += count
+		if metrics.NakamotoCoefficient == 0 && 2*cumulative > metrics.TotalCount {
+			metrics.NakamotoCoefficient = i + 1
+		}
+		// rank in ascending order, starting at 1
+		weightedSum += (n - float64(i)) * float64(count)
+	}
+	metrics.Gini = 2*weightedSum/(n*total) - (n+1)/n
+
+	for _, top := range topN {
+		var topCount uint64
+		for i := 0; i < top && i < len(sorted); i++ {
+			topCount += sorted[i]
+		}
+		metrics.TopShares[top] = float64(topCount) / total
+	}
+	return metrics
+}
+
+// Concentration returns the concentration metrics of all the groups,
+// regardless of the results limits
+func (g *GroupedActions) Concentration(topN []int) ConcentrationMetrics {
+	counts := make([]uint64, 0, len(g.Actions))
+	for _, group := range g.Actions {
+		counts = append(counts, group.Count)
+	}
+	return ComputeConcentration(counts, topN)
+}
+
+// TimeGroupedConcentration computes the concentration of the actions
+// on each of the properties separately, for every period
+type TimeGroupedConcentration struct {
+	Actions   map[time.Time][]*GroupedActions
+	Duration  Duration
+	GroupedBy ActionProperties
+	TopN      []int
+}
+
+func NewTimeGroupedConcentration(duration Duration, by ActionProperties, topN []int) *TimeGroupedConcentration {
+	return &TimeGroupedConcentration{
+		Actions:   make(map[time.Time][]*GroupedActions),
+		Duration:  duration,
+		GroupedBy: by,
+		TopN:      topN,
+	}
+}
+
+func (c *TimeGroupedConcentration) AddBlock(block Block) {
+	group := c.Duration.Truncate(block.Time())
+	actions, ok := c.Actions[group]
+	if !ok {
+		for _, property := range c.GroupedBy {
+			actions = append(actions, NewGroupedActions(ActionProperties{property}, false))
+		}
+		c.Actions[group] = actions
+	}
+	for _, groupedActions := range actions {
+		groupedActions.AddBlock(block)
+	}
+}
+
+// Metrics returns the concentration metrics of each period and property
+func (c *TimeGroupedConcentration) Metrics() map[time.Time]map[string]ConcentrationMetrics {
+	result := make(map[time.Time]map[string]ConcentrationMetrics)
+	for group, actions := range c.Actions {
+		result[group] = make(map[string]ConcentrationMetrics)
+		for i, property := range c.GroupedBy {
+			result[group][property.String()] = actions[i].Concentration(c.TopN)
+		}
+	}
+	return result
+}
+
+func (c *TimeGroupedConcentration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"Duration":  c.Duration,
+		"GroupedBy": c.GroupedBy,
+		"TopN":      c.TopN,
+		"Metrics":   c.Metrics(),
+	})
+}
+
+func (c *TimeGroupedConcentration) Result() interface{} {
+	return c
+}
@@ -0,0 +1,46 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComputeConcentration(t *testing.T) {
+	metrics := ComputeConcentration([]uint64{5, 5, 5, 5}, []int{1, 10})
+	assert.Equal(t, uint64(20), metrics.TotalCount)
+	assert.Equal(t, 4, metrics.UniqueCount)
+	assert.InDelta(t, 0.0, metrics.Gini, 1e-9)
+	assert.InDelta(t, 0.25, metrics.HHI, 1e-9)
+	assert.InDelta(t, 0.25, metrics.TopShares[1], 1e-9)
+	assert.InDelta(t, 1.0, metrics.TopShares[10], 1e-9)
+	assert.Equal(t, 3, metrics.NakamotoCoefficient)
+
+	metrics = ComputeConcentration([]uint64{0, 0, 0, 10}, []int{1})
+	assert.InDelta(t, 0.75, metrics.Gini, 1e-9)
+	assert.InDelta(t, 1.0, metrics.HHI, 1e-9)
+	assert.Equal(t, 1, metrics.NakamotoCoefficient)
+
+	metrics = ComputeConcentration([]uint64{1, 2, 3, 4}, nil)
+	assert.InDelta(t, 0.25, metrics.Gini, 1e-9)
+	assert.InDelta(t, 0.3, metrics.HHI, 1e-9)
+	assert.Equal(t, 2, metrics.NakamotoCoefficient)
+
+	metrics = ComputeConcentration(nil, []int{1})
+	assert.Equal(t, 0, metrics.NakamotoCoefficient)
+}
+
+func TestTimeGroupedConcentration(t *testing.T) {
+	concentration := NewTimeGroupedConcentration(NewDuration(time.Hour),
+		DefaultConcentrationProperties, []int{1})
+	concentration.AddBlock(newTestBlock())
+	metrics := concentration.Metrics()
+	group := time.Date(2020, 3, 1, 12, 0, 0, 0, time.UTC)
+	assert.Len(t, metrics, 1)
+	assert.Equal(t, 2, metrics[group]["name"].UniqueCount)
+	assert.InDelta(t, 2.0/3.0, metrics[group]["name"].TopShares[1], 1e-9)
+	assert.Equal(t, 2, metrics[group]["sender"].UniqueCount)
+	assert.Equal(t, 3, metrics[group]["receiver"].UniqueCount)
+	assert.Equal(t, 2, metrics[group]["receiver"].NakamotoCoefficient)
+}
@@ -4,25 +4,102 @@ import (
 	"encoding/json"
 	"fmt"
 	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/danhper/structomap"
 )
 
-type ActionProperty int
+type actionPropertyKind int
 
 const (
-	ActionName ActionProperty = iota
-	ActionSender
-	ActionReceiver
+	nameProperty actionPropertyKind = iota
+	senderProperty
+	receiverProperty
+	hourProperty
+	weekdayProperty
+	statusProperty
+	dataProperty
+	categoryProperty
 )
 
+// ActionProperty is a property of an action used to group actions
+// Data properties are decoded fields of the action data, optionally
+// truncated to their first characters, e.g. data.memo or data.memo:8
+// Category properties map another property, by default the receiver,
+// to its category, e.g. category or category.sender
+type ActionProperty struct {
+	kind       actionPropertyKind
+	field      string
+	length     int
+	base       *ActionProperty
+	categories *Categories
+}
+
+const categoryPropertyName = "category"
+
+var (
+	ActionName     = ActionProperty{kind: nameProperty}
+	ActionSender   = ActionProperty{kind: senderProperty}
+	ActionReceiver = ActionProperty{kind: receiverProperty}
+	ActionHour     = ActionProperty{kind: hourProperty}
+	ActionWeekday  = ActionProperty{kind: weekdayProperty}
+	ActionStatus   = ActionProperty{kind: statusProperty}
+)
+
+// ActionData returns the property of the given data field, truncated
+// to length characters if length is positive
+func ActionData(field string, length int) ActionProperty {
+	return ActionProperty{kind: dataProperty, field: field, length: length}
+}
+
+// Default number of results kept when serializing grouped actions
+// A limit of 0 means that all the results are kept
 const (
-	maxTopLevelResults = 1000
-	maxNestedResults   = 50
+	DefaultTopLevelResults = 1000
+	DefaultNestedResults   = 50
 )
 
-func GetActionProperty(name string) (ActionProperty, error) {
+func getDataProperty(name string) (ActionProperty, error) {
+	field := strings.TrimPrefix(name, dataFieldPrefix)
+	length := 0
+	if index := strings.LastIndex(field, ":"); index >= 0 {
+		parsed, err := strconv.Atoi(field[index+1:])
+		if err != nil || parsed <= 0 {
+			return ActionName, fmt.Errorf("invalid length in property %s", name)
+		}
+		field, length = field[:index], parsed
+	}
+	if field == "" {
+		return ActionName, fmt.Errorf("no field given in property %s", name)
+	}
+	return ActionData(field, length), nil
+}
+
+// ActionCategory returns the property mapping base to its category
+func ActionCategory(base ActionProperty, categories *Categories) ActionProperty {
+	return ActionProperty{kind: categoryProperty, base: &base, categories: categories}
+}
+
+func getCategoryProperty(name string, categories *Categories) (ActionProperty, error) {
+	if name == categoryPropertyName {
+		return ActionCategory(ActionReceiver, categories), nil
+	}
+	baseName := strings.TrimPrefix(name, categoryPropertyName+".")
+	if strings.HasPrefix(baseName, categoryPropertyName) {
+		return ActionName, fmt.Errorf("invalid property %s", name)
+	}
+	base, err := parseActionProperty(baseName, categories)
+	if err != nil {
+		return ActionName, err
+	}
+	return ActionCategory(base, categories), nil
+}
+
+// parseActionProperty parses the property without checking
+// that category properties have categories
+func parseActionProperty(name string, categories *Categories) (ActionProperty, error) {
 	switch name {
 	case "name":
 		return ActionName, nil
@@ -30,35 +107,269 @@ func GetActionProperty(name string) (ActionProperty, error) {
 		return ActionSender, nil
 	case "receiver":
 		return ActionReceiver, nil
+	case "hour":
+		return ActionHour, nil
+	case "weekday":
+		return ActionWeekday, nil
+	case "status":
+		return ActionStatus, nil
 	default:
+		if strings.HasPrefix(name, dataFieldPrefix) {
+			return getDataProperty(name)
+		}
+		if name == categoryPropertyName || strings.HasPrefix(name, categoryPropertyName+".") {
+			return getCategoryProperty(name, categories)
+		}
 		return ActionName, fmt.Errorf("no property %s for actions", name)
 	}
 }
 
+// GetActionProperty returns the property with the given name, where
+// categories are used by category properties and may be nil otherwise
+func GetActionProperty(name string, categories *Categories) (ActionProperty, error) {
+	property, err := parseActionProperty(name, categories)
+	if err != nil {
+		return ActionName, err
+	}
+	return property.WithCategories(categories)
+}
+
+// WithCategories returns the property using the given categories if it is a category
+// property, which fails if categories is nil, and the property itself otherwise
+func (p ActionProperty) WithCategories(categories *Categories) (ActionProperty, error) {
+	if p.kind != categoryProperty {
+		return p, nil
+	}
+	if categories == nil {
+		return ActionName, fmt.Errorf("property %s requires a categories file", p.String())
+	}
+	return ActionCategory(*p.base, categories), nil
+}
+
 func (p ActionProperty) String() string {
-	switch p {
-	case ActionName:
+	switch p.kind {
+	case nameProperty:
 		return "name"
-	case ActionSender:
+	case senderProperty:
 		return "sender"
-	case ActionReceiver:
+	case receiverProperty:
 		return "receiver"
+	case hourProperty:
+		return "hour"
+	case weekdayProperty:
+		return "weekday"
+	case statusProperty:
+		return "status"
+	case dataProperty:
+		if p.length > 0 {
+			return fmt.Sprintf("%s%s:%d", dataFieldPrefix, p.field, p.length)
+		}
+		return dataFieldPrefix + p.field
+	case categoryProperty:
+		if p.base.kind == receiverProperty {
+			return categoryPropertyName
+		}
+		return categoryPropertyName + "." + p.base.String()
 	default:
 		panic(fmt.Errorf("no such action property"))
 	}
 }
 
+// Get returns the value of the property for the given action
+// hour and weekday are properties of the block, in UTC
+func (p ActionProperty) Get(block Block, action Action) string {
+	switch p.kind {
+	case nameProperty:
+		return action.Name()
+	case senderProperty:
+		return action.Sender()
+	case receiverProperty:
+		return action.Receiver()
+	case hourProperty:
+		return fmt.Sprintf("%02d", block.Time().UTC().Hour())
+	case weekdayProperty:
+		return block.Time().UTC().Weekday().String()
+	case statusProperty:
+		return action.Status().String()
+	case dataProperty:
+		value := GetDataField(action, p.field)
+		if p.length > 0 {
+			if runes := []rune(value); len(runes) > p.length {
+				value = string(runes[:p.length])
+			}
+		}
+		return value
+	case categoryProperty:
+		return p.categories.Get(p.base.Get(block, action))
+	default:
+		panic(fmt.Errorf("no such property %d", p.kind))
+	}
+}
+
+// UnmarshalJSON parses the property without categories, which must
+// be set with WithCategories before using category properties
 func (c *ActionProperty) UnmarshalJSON(data []byte) (err error) {
 	var rawProperty string
 	if err = json.Unmarshal(data, &rawProperty); err != nil {
 		return err
 	}
-	*c, err = GetActionProperty(rawProperty)
+	*c, err = parseActionProperty(rawProperty, nil)
 	return err
 }
 
+func (c ActionProperty) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.String())
+}
+
+// ActionProperties is a list of properties used to build composite keys,
+// e.g. sender,receiver
+type ActionProperties []ActionProperty
+
+func GetActionProperties(names string, categories *Categories) (ActionProperties, error) {
+	var properties ActionProperties
+	for _, name := range strings.Split(names, ",") {
+		property, err := GetActionProperty(strings.TrimSpace(name), categories)
+		if err != nil {
+			return nil, err
+		}
+		properties = append(properties, property)
+	}
+	return properties, nil
+}
+
+// WithCategories returns the properties with categories set for the category properties
+func (p ActionProperties) WithCategories(categories *Categories) (ActionProperties, error) {
+	properties := make(ActionProperties, len(p))
+	for i, property := range p {
+		var err error
+		if properties[i], err = property.WithCategories(categories); err != nil {
+			return nil, err
+		}
+	}
+	return properties, nil
+}
+
+func (p ActionProperties) String() string {
+	names := make([]string, len(p))
+	for i, property := range p {
+		names[i] = property.String()
+	}
+	return strings.Join(names, ",")
+}
+
+// Keys returns the value of each property for the given action
+func (p ActionProperties) Keys(block Block, action Action) []string {
+	keys := make([]string, len(p))
+	for i, property := range p {
+		keys[i] = property.Get(block, action)
+	}
+	return keys
+}
+
+// groupKey returns an unambiguous map key for composite keys, as property
+// values, such as memos or data fields, may contain commas
+func groupKey(keys []string) string {
+	return strings.Join(keys, "\x00")
+}
+
+// NonEmptyKeys returns the keys of the action or nil if any of the keys is empty
+func (p ActionProperties) NonEmptyKeys(block Block, action Action) []string {
+	keys := p.Keys(block, action)
+	for _, key := range keys {
+		if key == "" {
+			return nil
+		}
+	}
+	return keys
+}
+
+// UnmarshalJSON accepts both a single property and a list of properties
+func (p *ActionProperties) UnmarshalJSON(data []byte) error {
+	var properties []ActionProperty
+	if err := json.Unmarshal(data, &properties); err == nil {
+		if len(properties) == 0 {
+			return fmt.Errorf("at least one property is required")
+		}
+		*p = properties
+		return nil
+	}
+	var property ActionProperty
+	if err := json.Unmarshal(data, &property); err != nil {
+		return err
+	}
+	*p = ActionProperties{property}
+	return nil
+}
+
+func (p ActionProperties) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.String())
+}
+
+// Duration is either a fixed duration or a number of calendar months
 type Duration struct {
 	time.Duration
+	Months int
+}
+
+func NewDuration(duration time.Duration) Duration {
+	return Duration{Duration: duration}
+}
+
+// ParseDuration accepts Go durations (e.g. 6h) as well as day, week, month and Nmo (e.g. 3mo)
+func ParseDuration(value string) (Duration, error) {
+	switch value {
+	case "day":
+		return NewDuration(24 * time.Hour), nil
+	case "week":
+		return NewDuration(7 * 24 * time.Hour), nil
+	case "month":
+		return Duration{Months: 1}, nil
+	}
+	if strings.HasSuffix(value, "mo") {
+		months, err := strconv.Atoi(strings.TrimSuffix(value, "mo"))
+		if err != nil || months <= 0 {
+			return Duration{}, fmt.Errorf("invalid number of months in %s", value)
+		}
+		return Duration{Months: months}, nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return Duration{}, err
+	}
+	if duration <= 0 {
+		return Duration{}, fmt.Errorf("duration must be positive, got %s", value)
+	}
+	return NewDuration(duration), nil
+}
+
+func (d Duration) IsZero() bool {
+	return d.Duration == 0 && d.Months == 0
+}
+
+// Truncate returns the start of the period containing t
+// Weeks start on Monday and months on the first day of the month, in UTC
+func (d Duration) Truncate(t time.Time) time.Time {
+	if d.Months == 0 {
+		return t.Truncate(d.Duration)
+	}
+	t = t.UTC()
+	months := (t.Year()*12 + int(t.Month()) - 1) / d.Months * d.Months
+	return time.Date(months/12, time.Month(months%12+1), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// Next returns the start of the period following the one containing t
+func (d Duration) Next(t time.Time) time.Time {
+	if d.Months == 0 {
+		return d.Truncate(t).Add(d.Duration)
+	}
+	return d.Truncate(t).AddDate(0, d.Months, 0)
+}
+
+func (d Duration) String() string {
+	if d.Months > 0 {
+		return fmt.Sprintf("%dmo", d.Months)
+	}
+	return d.Duration.String()
 }
 
 func (d *Duration) UnmarshalJSON(b []byte) (err error) {
@@ -66,19 +377,25 @@ func (d *Duration) UnmarshalJSON(b []byte) (err error) {
 	if err = json.Unmarshal(b, &rawDuration); err != nil {
 		return err
 	}
-	d.Duration, err = time.ParseDuration(rawDuration)
+	*d, err = ParseDuration(rawDuration)
 	return err
 }
 
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
 type ActionsCount struct {
 	Actions     map[string]uint64
 	UniqueCount uint64
 	TotalCount  uint64
+	limit       int
 }
 
 func NewActionsCount() *ActionsCount {
 	return &ActionsCount{
 		Actions: make(map[string]uint64),
+		limit:   DefaultNestedResults,
 	}
 }
 
@@ -105,24 +422,42 @@ type NamedCount struct {
 	Count uint64
 }
 
+// OthersCount aggregates the results removed when truncating
+type OthersCount struct {
+	UniqueCount uint64
+	Count       uint64
+}
+
 var actionsCountSerializer = structomap.New().
-	PickFunc(func(actions interface{}) interface{} {
-		var results []NamedCount
-		for name, count := range actions.(map[string]uint64) {
-			results = append(results, NamedCount{Name: name, Count: count})
-		}
-		sort.Slice(results, func(i, j int) bool {
-			return results[i].Count > results[j].Count
-		})
-		if len(results) > maxNestedResults {
-			results = results[:maxNestedResults]
-		}
-		return results
-	}, "Actions").
 	Pick("UniqueCount", "TotalCount")
 
+func (a *ActionsCount) truncate() ([]NamedCount, *OthersCount) {
+	var results []NamedCount
+	for name, count := range a.Actions {
+		results = append(results, NamedCount{Name: name, Count: count})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Count > results[j].Count
+	})
+	if a.limit == 0 || len(results) <= a.limit {
+		return results, nil
+	}
+	others := &OthersCount{}
+	for _, result := range results[a.limit:] {
+		others.UniqueCount++
+		others.Count += result.Count
+	}
+	return results[:a.limit], others
+}
+
 func (a *ActionsCount) MarshalJSON() ([]byte, error) {
-	return json.Marshal(actionsCountSerializer.Transform(a))
+	result := actionsCountSerializer.Transform(a)
+	actions, others := a.truncate()
+	result["Actions"] = actions
+	if others != nil {
+		result["Others"] = others
+	}
+	return json.Marshal(result)
 }
 
 func Persist(entity interface{}, outputFile string) error {
@@ -136,23 +471,37 @@ func Persist(entity interface{}, outputFile string) error {
 }
 
 type TimeGroupedActions struct {
-	Actions   map[time.Time]*GroupedActions
-	Duration  time.Duration
-	GroupedBy ActionProperty
+	Actions     map[time.Time]*GroupedActions
+	Duration    Duration
+	GroupedBy   ActionProperties
+	topLimit    int
+	nestedLimit int
 }
 
-func NewTimeGroupedActions(duration time.Duration, by ActionProperty) *TimeGroupedActions {
+func NewTimeGroupedActions(duration Duration, by ActionProperties) *TimeGroupedActions {
 	return &TimeGroupedActions{
-		Actions:   make(map[time.Time]*GroupedActions),
-		Duration:  duration,
-		GroupedBy: by,
+		Actions:     make(map[time.Time]*GroupedActions),
+		Duration:    duration,
+		GroupedBy:   by,
+		topLimit:    DefaultTopLevelResults,
+		nestedLimit: DefaultNestedResults,
+	}
+}
+
+// SetResultsLimits sets the number of results kept in each time group
+func (g *TimeGroupedActions) SetResultsLimits(top, nested int) *TimeGroupedActions {
+	g.topLimit, g.nestedLimit = top, nested
+	for _, actions := range g.Actions {
+		actions.SetResultsLimits(top, nested)
 	}
+	return g
 }
 
 func (g *TimeGroupedActions) AddBlock(block Block) {
-	group := block.Time().Truncate(g.Duration)
+	group := g.Duration.Truncate(block.Time())
 	if _, ok := g.Actions[group]; !ok {
-		g.Actions[group] = NewGroupedActions(g.GroupedBy, false)
+		g.Actions[group] = NewGroupedActions(g.GroupedBy, false).
+			SetResultsLimits(g.topLimit, g.nestedLimit)
 	}
 	g.Actions[group].AddBlock(block)
 }
@@ -163,10 +512,10 @@ func (g *TimeGroupedActions) Result() interface{} {
 
 type TimeGroupedTransactionCount struct {
 	TransactionCounts map[time.Time]int
-	GroupedBy         time.Duration
+	GroupedBy         Duration
 }
 
-func NewTimeGroupedTransactionCount(duration time.Duration) *TimeGroupedTransactionCount {
+func NewTimeGroupedTransactionCount(duration Duration) *TimeGroupedTransactionCount {
 	return &TimeGroupedTransactionCount{
 		TransactionCounts: make(map[time.Time]int),
 		GroupedBy:         duration,
@@ -174,7 +523,7 @@ func NewTimeGroupedTransactionCount(duration time.Duration) *TimeGroupedTransact
 }
 
 func (g *TimeGroupedTransactionCount) AddBlock(block Block) {
-	group := block.Time().Truncate(g.GroupedBy)
+	group := g.GroupedBy.Truncate(block.Time())
 	if _, ok := g.TransactionCounts[group]; !ok {
 		g.TransactionCounts[group] = 0
 	}
@@ -187,6 +536,7 @@ func (g *TimeGroupedTransactionCount) Result() interface{} {
 
 type ActionGroup struct {
 	Name      string
+	Keys      []string
 	Count     uint64
 	Names     *ActionsCount
 	Senders   *ActionsCount
@@ -194,7 +544,11 @@ type ActionGroup struct {
 }
 
 var actionGroupSerializer = structomap.New().
-	Pick("Name", "Count").
+	Pick("Name").
+	PickIf(func(a interface{}) bool {
+		return len(a.(*ActionGroup).Keys) > 1
+	}, "Keys").
+	Pick("Count").
 	PickIf(func(a interface{}) bool {
 		return a.(*ActionGroup).Names.TotalCount > 0
 	}, "Names", "Senders", "Receivers")
@@ -203,9 +557,16 @@ func (a *ActionGroup) MarshalJSON() ([]byte, error) {
 	return json.Marshal(actionGroupSerializer.Transform(a))
 }
 
-func NewActionGroup(name string) *ActionGroup {
+func (a *ActionGroup) setNestedLimit(limit int) {
+	a.Names.limit = limit
+	a.Senders.limit = limit
+	a.Receivers.limit = limit
+}
+
+func NewActionGroup(keys []string) *ActionGroup {
 	return &ActionGroup{
-		Name:      name,
+		Name:      strings.Join(keys, ","),
+		Keys:      keys,
 		Count:     0,
 		Names:     NewActionsCount(),
 		Senders:   NewActionsCount(),
@@ -213,69 +574,85 @@ func NewActionGroup(name string) *ActionGroup {
 	}
 }
 
+// GroupedActions counts the actions per group, where composite keys are
+// joined with a null byte in Actions and with a comma in the group names
 type GroupedActions struct {
-	Actions        map[string]*ActionGroup
-	GroupedBy      string
-	BlocksCount    uint64
-	ActionsCount   uint64
-	actionProperty ActionProperty
-	detailed       bool
+	Actions          map[string]*ActionGroup
+	GroupedBy        string
+	BlocksCount      uint64
+	ActionsCount     uint64
+	actionProperties ActionProperties
+	detailed         bool
+	topLimit         int
+	nestedLimit      int
 }
 
 var groupedActionsSerializer = structomap.New().
-	PickFunc(func(actions interface{}) interface{} {
-		var results []*ActionGroup
-		for _, action := range actions.(map[string]*ActionGroup) {
-			results = append(results, action)
-		}
-		sort.Slice(results, func(i, j int) bool {
-			return results[i].Count > results[j].Count
-		})
-		if len(results) > maxTopLevelResults {
-			results = results[:maxTopLevelResults]
-		}
-		return results
-	}, "Actions").
 	Pick("GroupedBy", "BlocksCount", "ActionsCount")
 
+func (g *GroupedActions) truncate() ([]*ActionGroup, *OthersCount) {
+	var results []*ActionGroup
+	for _, action := range g.Actions {
+		results = append(results, action)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Count > results[j].Count
+	})
+	if g.topLimit == 0 || len(results) <= g.topLimit {
+		return results, nil
+	}
+	others := &OthersCount{}
+	for _, result := range results[g.topLimit:] {
+		others.UniqueCount++
+		others.Count += result.Count
+	}
+	return results[:g.topLimit], others
+}
+
 func (g *GroupedActions) MarshalJSON() ([]byte, error) {
-	return json.Marshal(groupedActionsSerializer.Transform(g))
+	result := groupedActionsSerializer.Transform(g)
+	actions, others := g.truncate()
+	result["Actions"] = actions
+	if others != nil {
+		result["Others"] = others
+	}
+	return json.Marshal(result)
+}
+
+// SetResultsLimits sets the number of groups and the number of nested
+// results kept in each group when serializing, 0 meaning unlimited
+func (g *GroupedActions) SetResultsLimits(top, nested int) *GroupedActions {
+	g.topLimit, g.nestedLimit = top, nested
+	for _, group := range g.Actions {
+		group.setNestedLimit(nested)
+	}
+	return g
 }
 
-func (g *GroupedActions) Get(key string) *ActionGroup {
-	return g.Actions[key]
+// Get returns the group of the given keys, one per grouping property
+func (g *GroupedActions) Get(keys ...string) *ActionGroup {
+	return g.Actions[groupKey(keys)]
 }
 
-func (g *GroupedActions) GetCount(key string) uint64 {
-	group := g.Get(key)
+func (g *GroupedActions) GetCount(keys ...string) uint64 {
+	group := g.Get(keys...)
 	if group == nil {
 		return 0
 	}
 	return group.Count
 }
 
-func NewGroupedActions(by ActionProperty, detailed bool) *GroupedActions {
+func NewGroupedActions(by ActionProperties, detailed bool) *GroupedActions {
 	actions := make(map[string]*ActionGroup)
 	return &GroupedActions{
-		Actions:        actions,
-		GroupedBy:      by.String(),
-		BlocksCount:    0,
-		ActionsCount:   0,
-		actionProperty: by,
-		detailed:       detailed,
-	}
-}
-
-func (g *GroupedActions) getActionKey(action Action) string {
-	switch g.actionProperty {
-	case ActionName:
-		return action.Name()
-	case ActionSender:
-		return action.Sender()
-	case ActionReceiver:
-		return action.Receiver()
-	default:
-		panic(fmt.Errorf("no such property %d", g.actionProperty))
+		Actions:          actions,
+		GroupedBy:        by.String(),
+		BlocksCount:      0,
+		ActionsCount:     0,
+		actionProperties: by,
+		detailed:         detailed,
+		topLimit:         DefaultTopLevelResults,
+		nestedLimit:      DefaultNestedResults,
 	}
 }
 
@@ -283,13 +660,15 @@ func (g *GroupedActions) AddBlock(block Block) {
 	g.BlocksCount += 1
 	for _, action := range block.ListActions() {
 		g.ActionsCount += 1
-		key := g.getActionKey(action)
-		if key == "" {
+		keys := g.actionProperties.NonEmptyKeys(block, action)
+		if keys == nil {
 			continue
 		}
+		key := groupKey(keys)
 		actionGroup, ok := g.Actions[key]
 		if !ok {
-			actionGroup = NewActionGroup(key)
+			actionGroup = NewActionGroup(keys)
+			actionGroup.setNestedLimit(g.nestedLimit)
 			g.Actions[key] = actionGroup
 		}
 		actionGroup.Count += 1
@@ -1,18 +1,248 @@
 package core
 
 import (
+	"bytes"
+	"encoding/json"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
 
 func TestGetActionProperty(t *testing.T) {
-	prop, err := GetActionProperty("name")
+	prop, err := GetActionProperty("name", nil)
 	assert.Nil(t, err)
 	assert.Equal(t, ActionName, prop)
-	prop, err = GetActionProperty("sender")
+	prop, err = GetActionProperty("sender", nil)
 	assert.Nil(t, err)
 	assert.Equal(t, ActionSender, prop)
-	prop, err = GetActionProperty("other")
+	prop, err = GetActionProperty("other", nil)
 	assert.NotNil(t, err)
+
+	prop, err = GetActionProperty("data.memo:3", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, ActionData("memo", 3), prop)
+	assert.Equal(t, "data.memo:3", prop.String())
+	prop, err = GetActionProperty("data.quotes.0.pair", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, ActionData("quotes.0.pair", 0), prop)
+	_, err = GetActionProperty("data.memo:0", nil)
+	assert.NotNil(t, err)
+	_, err = GetActionProperty("data.", nil)
+	assert.NotNil(t, err)
+}
+
+type dataTestAction struct {
+	testAction
+	fields map[string]string
+}
+
+func (a dataTestAction) DataField(path string) (string, bool) {
+	value, ok := a.fields[path]
+	return value, ok
+}
+
+func TestDataProperty(t *testing.T) {
+	block := newTestBlock()
+	action := dataTestAction{testAction{"transfer", "alice", "eosio.token"},
+		map[string]string{"memo": "deposit:42", "to": "bob"}}
+	assert.Equal(t, "dep", ActionData("memo", 3).Get(block, action))
+	assert.Equal(t, "deposit:42", ActionData("memo", 0).Get(block, action))
+	assert.Equal(t, "", ActionData("other", 0).Get(block, action))
+	assert.Equal(t, "", ActionData("memo", 0).Get(block, block.actions[0]))
+
+	filter, err := ParseFilter(`data.to == "bob" && data.memo =~ "^deposit"`)
+	assert.Nil(t, err)
+	assert.True(t, filter.Matches(block, action))
+	assert.False(t, filter.Matches(block, block.actions[0]))
+}
+
+func TestGetActionProperties(t *testing.T) {
+	props, err := GetActionProperties("sender, receiver", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, ActionProperties{ActionSender, ActionReceiver}, props)
+	assert.Equal(t, "sender,receiver", props.String())
+	props, err = GetActionProperties("weekday", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, ActionProperties{ActionWeekday}, props)
+	_, err = GetActionProperties("sender,other", nil)
+	assert.NotNil(t, err)
+}
+
+func TestUnmarshalActionProperties(t *testing.T) {
+	var props ActionProperties
+	assert.Nil(t, json.Unmarshal([]byte(`"receiver"`), &props))
+	assert.Equal(t, ActionProperties{ActionReceiver}, props)
+	assert.Nil(t, json.Unmarshal([]byte(`["receiver", "name"]`), &props))
+	assert.Equal(t, ActionProperties{ActionReceiver, ActionName}, props)
+	assert.NotNil(t, json.Unmarshal([]byte(`[]`), &props))
+	assert.NotNil(t, json.Unmarshal([]byte(`["receiver", "other"]`), &props))
+}
+
+func TestGroupedActionsCompositeKeys(t *testing.T) {
+	block := newTestBlock()
+	grouped := NewGroupedActions(ActionProperties{ActionSender, ActionName}, false)
+	grouped.AddBlock(block)
+	assert.Equal(t, uint64(2), grouped.GetCount("alice", "transfer")+grouped.GetCount("alice", "bet"))
+	assert.Equal(t, []string{"bob", "transfer"}, grouped.Get("bob", "transfer").Keys)
+
+	rawGroup, err := json.Marshal(grouped.Get("bob", "transfer"))
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"Name": "bob,transfer", "Keys": ["bob", "transfer"], "Count": 1}`, string(rawGroup))
+
+	grouped = NewGroupedActions(ActionProperties{ActionHour, ActionWeekday}, false)
+	grouped.AddBlock(block)
+	assert.Equal(t, uint64(3), grouped.GetCount("12", "Sunday"))
+
+	// values containing commas do not collide
+	block = newTestBlock()
+	block.actions = []Action{
+		testAction{"transfer", "a,b", "c"},
+		testAction{"transfer", "a", "b,c"},
+	}
+	grouped = NewGroupedActions(ActionProperties{ActionSender, ActionReceiver}, false)
+	grouped.AddBlock(block)
+	assert.Len(t, grouped.Actions, 2)
+	assert.Equal(t, uint64(1), grouped.GetCount("a,b", "c"))
+	assert.Equal(t, "a,b,c", grouped.Get("a", "b,c").Name)
+}
+
+func TestGroupedActionsResultsLimits(t *testing.T) {
+	grouped := NewGroupedActions(ActionProperties{ActionSender}, true)
+	grouped.AddBlock(newTestBlock())
+
+	var result struct {
+		Actions []struct {
+			Name      string
+			Receivers struct {
+				Actions []NamedCount
+				Others  *OthersCount
+			}
+		}
+		Others *OthersCount
+	}
+	rawResult, err := json.Marshal(grouped)
+	assert.Nil(t, err)
+	assert.Nil(t, json.Unmarshal(rawResult, &result))
+	assert.Len(t, result.Actions, 2)
+	assert.Nil(t, result.Others)
+
+	grouped.SetResultsLimits(1, 1)
+	rawResult, err = json.Marshal(grouped)
+	assert.Nil(t, err)
+	assert.Nil(t, json.Unmarshal(rawResult, &result))
+	assert.Len(t, result.Actions, 1)
+	assert.Equal(t, "alice", result.Actions[0].Name)
+	assert.Equal(t, &OthersCount{UniqueCount: 1, Count: 1}, result.Others)
+	assert.Len(t, result.Actions[0].Receivers.Actions, 1)
+	assert.Equal(t, &OthersCount{UniqueCount: 1, Count: 1}, result.Actions[0].Receivers.Others)
+
+	grouped.SetResultsLimits(0, 0)
+	result.Others = nil
+	rawResult, err = json.Marshal(grouped)
+	assert.Nil(t, err)
+	assert.Nil(t, json.Unmarshal(rawResult, &result))
+	assert.Len(t, result.Actions, 2)
+	assert.Nil(t, result.Others)
+}
+
+func TestParseDuration(t *testing.T) {
+	duration, err := ParseDuration("6h")
+	assert.Nil(t, err)
+	assert.Equal(t, NewDuration(6*time.Hour), duration)
+	duration, err = ParseDuration("week")
+	assert.Nil(t, err)
+	assert.Equal(t, NewDuration(7*24*time.Hour), duration)
+	duration, err = ParseDuration("3mo")
+	assert.Nil(t, err)
+	assert.Equal(t, Duration{Months: 3}, duration)
+	assert.Equal(t, "3mo", duration.String())
+	_, err = ParseDuration("0mo")
+	assert.NotNil(t, err)
+	_, err = ParseDuration("-1h")
+	assert.NotNil(t, err)
+}
+
+func TestDurationTruncate(t *testing.T) {
+	blockTime := time.Date(2020, 5, 16, 0, 10, 43, 0, time.UTC)
+	month, _ := ParseDuration("month")
+	assert.Equal(t, time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC), month.Truncate(blockTime))
+	assert.Equal(t, time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC), month.Next(blockTime))
+	quarter, _ := ParseDuration("3mo")
+	assert.Equal(t, time.Date(2020, 4, 1, 0, 0, 0, 0, time.UTC), quarter.Truncate(blockTime))
+	week, _ := ParseDuration("week")
+	assert.Equal(t, time.Date(2020, 5, 11, 0, 0, 0, 0, time.UTC), week.Truncate(blockTime))
+	assert.Equal(t, time.Monday, week.Truncate(blockTime).Weekday())
+}
+
+func TestTimeGroupedActiveAccounts(t *testing.T) {
+	day, _ := ParseDuration("day")
+	activeAccounts := NewTimeGroupedActiveAccounts(day)
+	block := newTestBlock()
+	activeAccounts.AddBlock(block)
+	block = newTestBlock()
+	block.actions = append(block.actions, testAction{"transfer", "eosio.token", "carol"})
+	activeAccounts.AddBlock(block)
+	accounts := activeAccounts.Accounts[time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)]
+	assert.Equal(t, 3, accounts.SendersCount())
+	assert.Equal(t, 4, accounts.ReceiversCount())
+	assert.Equal(t, 6, accounts.ParticipantsCount())
+}
+
+func TestFirstSeenAccounts(t *testing.T) {
+	day, _ := ParseDuration("day")
+	firstSeen := NewFirstSeenAccounts(day, "")
+	laterBlock := newTestBlock()
+	laterBlock.number = 200
+	laterBlock.time = laterBlock.time.Add(24 * time.Hour)
+	laterBlock.actions = append(laterBlock.actions, testAction{"transfer", "carol", "alice"})
+	firstSeen.AddBlock(laterBlock)
+	firstSeen.AddBlock(newTestBlock())
+
+	assert.Len(t, firstSeen.Accounts, 6)
+	assert.Equal(t, FirstSeen{Block: 100, Time: newTestBlock().time, Action: "transfer"}, firstSeen.Accounts["alice"])
+	assert.Equal(t, uint64(200), firstSeen.Accounts["carol"].Block)
+	assert.Equal(t, map[time.Time]uint64{
+		time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC): 5,
+		time.Date(2020, 3, 2, 0, 0, 0, 0, time.UTC): 1,
+	}, firstSeen.NewAccountsOverTime())
+
+	var output bytes.Buffer
+	assert.Nil(t, firstSeen.WriteCSV(&output))
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	assert.Len(t, lines, 7)
+	assert.Equal(t, "account,first_block,first_time,first_action", lines[0])
+	assert.Equal(t, "alice,100,2020-03-01T12:00:00Z,transfer", lines[1])
+	assert.Equal(t, "carol,200,2020-03-02T12:00:00Z,transfer", lines[6])
+}
+
+func TestRetention(t *testing.T) {
+	day, _ := ParseDuration("day")
+	retention := NewRetention(day)
+	firstDay := newTestBlock()
+	retention.AddBlock(firstDay)
+
+	thirdDay := newTestBlock()
+	thirdDay.time = thirdDay.time.Add(48 * time.Hour)
+	thirdDay.actions = []Action{
+		testAction{"transfer", "alice", "bob"},
+		testAction{"transfer", "carol", "bob"},
+	}
+	retention.AddBlock(thirdDay)
+
+	secondDay := newTestBlock()
+	secondDay.time = secondDay.time.Add(24 * time.Hour)
+	secondDay.actions = []Action{testAction{"transfer", "dave", "bob"}}
+	retention.AddBlock(secondDay)
+
+	cohorts := retention.Cohorts()
+	assert.Len(t, cohorts, 3)
+	assert.Equal(t, time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC), cohorts[0].Cohort)
+	assert.Equal(t, uint64(2), cohorts[0].Size)
+	assert.Equal(t, []uint64{2, 0, 1}, cohorts[0].Active)
+	assert.Equal(t, []uint64{1, 0}, cohorts[1].Active)
+	assert.Equal(t, []uint64{1}, cohorts[2].Active)
+
+	assert.Len(t, NewRetention(day).Cohorts(), 0)
 }
@@ -0,0 +1,178 @@
+package core
+
+import (
+	"encoding/json"
+	"math/bits"
+	"sort"
+	"time"
+)
+
+const DefaultTDigestCompression = 100
+
+// HistogramBucket counts the values between Min and Max inclusive
+type HistogramBucket struct {
+	Min   uint64
+	Max   uint64
+	Count uint64
+}
+
+// Histogram counts values in power of two buckets: 0, 1, 2-3, 4-7, etc
+type Histogram map[int]uint64
+
+func (h Histogram) Add(value uint64) {
+	h[bits.Len64(value)]++
+}
+
+func (h Histogram) Merge(other Histogram) {
+	for bucket, count := range other {
+		h[bucket] += count
+	}
+}
+
+func (h Histogram) Buckets() []HistogramBucket {
+	buckets := make([]HistogramBucket, 0, len(h))
+	for bucket, count := range h {
+		var min, max uint64
+		if bucket > 0 {
+			min = 1 << (bucket - 1)
+			max = min<<1 - 1
+		}
+		buckets = append(buckets, HistogramBucket{Min: min, Max: max, Count: count})
+	}
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i].Min < buckets[j].Min })
+	return buckets
+}
+
+// DistributionSummary describes the distribution of a value over blocks
+// Percentiles are estimated while the other fields are exact
+type DistributionSummary struct {
+	Count     uint64
+	Total     uint64
+	Mean      float64
+	Min       uint64
+	P50       float64
+	P90       float64
+	P99       float64
+	Max       uint64
+	Histogram []HistogramBucket
+}
+
+// ValueDistribution is a mergeable summary of a stream of per-block values
+type ValueDistribution struct {
+	digest    *TDigest
+	histogram Histogram
+	total     uint64
+}
+
+func NewValueDistribution() *ValueDistribution {
+	digest, _ := NewTDigest(DefaultTDigestCompression)
+	return &ValueDistribution{digest: digest, histogram: make(Histogram)}
+}
+
+func (d *ValueDistribution) Add(value uint64) {
+	d.digest.Add(float64(value))
+	d.histogram.Add(value)
+	d.total += value
+}
+
+func (d *ValueDistribution) Merge(other *ValueDistribution) {
+	d.digest.Merge(other.digest)
+	d.histogram.Merge(other.histogram)
+	d.total += other.total
+}
+
+func (d *ValueDistribution) Summary() DistributionSummary {
+	summary := DistributionSummary{
+		Count:     d.digest.Count(),
+		Total:     d.total,
+		Min:       uint64(d.digest.Min()),
+		P50:       d.digest.Quantile(0.5),
+		P90:       d.digest.Quantile(0.9),
+		P99:       d.digest.Quantile(0.99),
+		Max:       uint64(d.digest.Max()),
+		Histogram: d.histogram.Buckets(),
+	}
+	if summary.Count > 0 {
+		summary.Mean = float64(d.total) / float64(summary.Count)
+	}
+	return summary
+}
+
+func (d *ValueDistribution) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.Summary())
+}
+
+// BlockDistributions are the distributions of the number of transactions
+// and actions per block
+type BlockDistributions struct {
+	Transactions *ValueDistribution
+	Actions      *ValueDistribution
+}
+
+func NewBlockDistributions() *BlockDistributions {
+	return &BlockDistributions{
+		Transactions: NewValueDistribution(),
+		Actions:      NewValueDistribution(),
+	}
+}
+
+func (d *BlockDistributions) AddBlock(block Block) {
+	d.Transactions.Add(uint64(block.TransactionsCount()))
+	d.Actions.Add(uint64(len(block.ListActions())))
+}
+
+func (d *BlockDistributions) Merge(other *BlockDistributions) {
+	d.Transactions.Merge(other.Transactions)
+	d.Actions.Merge(other.Actions)
+}
+
+// Distribution computes the distributions of transactions and actions
+// per block over the whole range and, if Duration is set, for each period
+type Distribution struct {
+	Duration Duration
+	Total    *BlockDistributions
+	OverTime map[time.Time]*BlockDistributions
+}
+
+func NewDistribution(duration Duration) *Distribution {
+	return &Distribution{
+		Duration: duration,
+		Total:    NewBlockDistributions(),
+		OverTime: make(map[time.Time]*BlockDistributions),
+	}
+}
+
+func (d *Distribution) AddBlock(block Block) {
+	d.Total.AddBlock(block)
+	if d.Duration.IsZero() {
+		return
+	}
+	group := d.Duration.Truncate(block.Time())
+	if _, ok := d.OverTime[group]; !ok {
+		d.OverTime[group] = NewBlockDistributions()
+	}
+	d.OverTime[group].AddBlock(block)
+}
+
+func (d *Distribution) Merge(other *Distribution) {
+	d.Total.Merge(other.Total)
+	for group, distributions := range other.OverTime {
+		if _, ok := d.OverTime[group]; !ok {
+			d.OverTime[group] = NewBlockDistributions()
+		}
+		d.OverTime[group].Merge(distributions)
+	}
+}
+
+func (d *Distribution) MarshalJSON() ([]byte, error) {
+	result := map[string]interface{}{"Total": d.Total}
+	if !d.Duration.IsZero() {
+		result["Duration"] = d.Duration
+		result["OverTime"] = d.OverTime
+	}
+	return json.Marshal(result)
+}
+
+func (d *Distribution) Result() interface{} {
+	return d
+}
@@ -0,0 +1,55 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistogram(t *testing.T) {
+	histogram := make(Histogram)
+	for _, value := range []uint64{0, 1, 2, 3, 4, 7, 8} {
+		histogram.Add(value)
+	}
+	other := make(Histogram)
+	other.Add(0)
+	histogram.Merge(other)
+	assert.Equal(t, []HistogramBucket{
+		{Min: 0, Max: 0, Count: 2},
+		{Min: 1, Max: 1, Count: 1},
+		{Min: 2, Max: 3, Count: 2},
+		{Min: 4, Max: 7, Count: 2},
+		{Min: 8, Max: 15, Count: 1},
+	}, histogram.Buckets())
+}
+
+func TestDistribution(t *testing.T) {
+	start := testBlockTime
+	distribution := NewDistribution(NewDuration(time.Hour))
+	other := NewDistribution(NewDuration(time.Hour))
+	for i := 0; i < 10; i++ {
+		block := newTestBlock()
+		block.time = start.Add(time.Duration(i) * 30 * time.Minute)
+		block.actions = block.actions[:i%4]
+		if i < 5 {
+			distribution.AddBlock(block)
+		} else {
+			other.AddBlock(block)
+		}
+	}
+	distribution.Merge(other)
+
+	actions := distribution.Total.Actions.Summary()
+	assert.Equal(t, uint64(10), actions.Count)
+	assert.Equal(t, uint64(13), actions.Total)
+	assert.Equal(t, uint64(0), actions.Min)
+	assert.Equal(t, uint64(3), actions.Max)
+	assert.InDelta(t, 1.3, actions.Mean, 1e-9)
+	assert.Len(t, distribution.OverTime, 5)
+	assert.Equal(t, uint64(2), distribution.OverTime[start].Transactions.Summary().Count)
+
+	withoutDuration := NewDistribution(Duration{})
+	withoutDuration.AddBlock(newTestBlock())
+	assert.Empty(t, withoutDuration.OverTime)
+}
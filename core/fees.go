@@ -0,0 +1,147 @@
+package core
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+)
+
+// Fee is an amount of a resource paid by the sender of a transaction,
+// e.g. a fee in drops or mutez or the CPU time used on EOS
+type Fee struct {
+	Resource string
+	Value    uint64
+}
+
+// FeeAction is implemented by actions whose transaction pays fees
+// When a transaction has several actions, only one of them returns the fees
+type FeeAction interface {
+	Fees() []Fee
+}
+
+// GetFees returns the fees paid by action, if any
+func GetFees(action Action) []Fee {
+	feeAction, ok := action.(FeeAction)
+	if !ok {
+		return nil
+	}
+	return feeAction.Fees()
+}
+
+// SenderFees is the number of transactions and the exact total, mean,
+// minimum and maximum fees paid by a sender for a resource
+type SenderFees struct {
+	Sender string
+	Count  uint64
+	Total  uint64
+	Mean   float64
+	Min    uint64
+	Max    uint64
+}
+
+func (s *SenderFees) add(value uint64) {
+	if s.Count == 0 || value < s.Min {
+		s.Min = value
+	}
+	if value > s.Max {
+		s.Max = value
+	}
+	s.Count++
+	s.Total += value
+	s.Mean = float64(s.Total) / float64(s.Count)
+}
+
+// FeesOverTime computes the distribution of the fees of each resource over
+// the whole range and per period, and the totals of each sender, for which
+// only exact totals are kept to bound the memory used per sender
+// Fees are returned by one action per transaction, usually the first, so with
+// a filter the fees of a transaction are only counted if this action matches
+type FeesOverTime struct {
+	Duration   Duration
+	Total      map[string]*ValueDistribution
+	OverTime   map[time.Time]map[string]*ValueDistribution
+	senders    map[string]map[string]*SenderFees
+	topSenders int
+}
+
+func NewFeesOverTime(duration Duration) *FeesOverTime {
+	return &FeesOverTime{
+		Duration:   duration,
+		Total:      make(map[string]*ValueDistribution),
+		OverTime:   make(map[time.Time]map[string]*ValueDistribution),
+		senders:    make(map[string]map[string]*SenderFees),
+		topSenders: DefaultNestedResults,
+	}
+}
+
+// SetTopSenders sets the number of senders output for each resource, 0 for unlimited
+func (f *FeesOverTime) SetTopSenders(limit int) *FeesOverTime {
+	f.topSenders = limit
+	return f
+}
+
+func addFee(distributions map[string]*ValueDistribution, key string, value uint64) {
+	if _, ok := distributions[key]; !ok {
+		distributions[key] = NewValueDistribution()
+	}
+	distributions[key].Add(value)
+}
+
+func (f *FeesOverTime) addSenderFee(resource, sender string, value uint64) {
+	if _, ok := f.senders[resource]; !ok {
+		f.senders[resource] = make(map[string]*SenderFees)
+	}
+	if _, ok := f.senders[resource][sender]; !ok {
+		f.senders[resource][sender] = &SenderFees{Sender: sender}
+	}
+	f.senders[resource][sender].add(value)
+}
+
+func (f *FeesOverTime) AddBlock(block Block) {
+	group := f.Duration.Truncate(block.Time())
+	for _, action := range block.ListActions() {
+		for _, fee := range GetFees(action) {
+			if _, ok := f.OverTime[group]; !ok {
+				f.OverTime[group] = make(map[string]*ValueDistribution)
+			}
+			addFee(f.Total, fee.Resource, fee.Value)
+			addFee(f.OverTime[group], fee.Resource, fee.Value)
+			f.addSenderFee(fee.Resource, action.Sender(), fee.Value)
+		}
+	}
+}
+
+// Senders returns the senders of the resource sorted by decreasing total fees
+func (f *FeesOverTime) Senders(resource string) []SenderFees {
+	senders := make([]SenderFees, 0, len(f.senders[resource]))
+	for _, fees := range f.senders[resource] {
+		senders = append(senders, *fees)
+	}
+	sort.Slice(senders, func(i, j int) bool {
+		if senders[i].Total == senders[j].Total {
+			return senders[i].Sender < senders[j].Sender
+		}
+		return senders[i].Total > senders[j].Total
+	})
+	if f.topSenders > 0 && len(senders) > f.topSenders {
+		senders = senders[:f.topSenders]
+	}
+	return senders
+}
+
+func (f *FeesOverTime) MarshalJSON() ([]byte, error) {
+	senders := make(map[string][]SenderFees)
+	for resource := range f.senders {
+		senders[resource] = f.Senders(resource)
+	}
+	return json.Marshal(map[string]interface{}{
+		"Duration": f.Duration,
+		"Total":    f.Total,
+		"OverTime": f.OverTime,
+		"Senders":  senders,
+	})
+}
+
+func (f *FeesOverTime) Result() interface{} {
+	return f
+}
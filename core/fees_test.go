@@ -0,0 +1,64 @@
+package core
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type feeTestAction struct {
+	testAction
+	fees []Fee
+}
+
+func (a feeTestAction) Fees() []Fee { return a.fees }
+
+func newFeeTestAction(sender string, fee, cpu uint64) Action {
+	return feeTestAction{
+		testAction{"transfer", sender, "eosio.token"},
+		[]Fee{{Resource: "fee", Value: fee}, {Resource: "cpu", Value: cpu}},
+	}
+}
+
+func TestGetFees(t *testing.T) {
+	assert.Nil(t, GetFees(testAction{"transfer", "alice", "eosio.token"}))
+	assert.Len(t, GetFees(newFeeTestAction("alice", 10, 100)), 2)
+}
+
+func TestFeesOverTime(t *testing.T) {
+	fees := NewFeesOverTime(NewDuration(time.Hour)).SetTopSenders(1)
+	block := newTestBlock()
+	block.actions = append(block.actions,
+		newFeeTestAction("alice", 10, 100), newFeeTestAction("bob", 30, 50))
+	later := newTestBlock()
+	later.time = later.time.Add(time.Hour)
+	later.actions = []Action{newFeeTestAction("alice", 12, 200)}
+	fees.AddBlock(block)
+	fees.AddBlock(later)
+
+	total := fees.Total["fee"].Summary()
+	assert.Equal(t, uint64(3), total.Count)
+	assert.Equal(t, uint64(52), total.Total)
+	assert.Equal(t, uint64(10), total.Min)
+	assert.Equal(t, uint64(30), total.Max)
+	assert.Equal(t, uint64(350), fees.Total["cpu"].Summary().Total)
+
+	assert.Len(t, fees.OverTime, 2)
+	assert.Equal(t, uint64(40), fees.OverTime[block.time]["fee"].Summary().Total)
+	assert.InDelta(t, 200, fees.OverTime[later.time]["cpu"].Summary().Mean, 1e-9)
+
+	senders := fees.Senders("fee")
+	if assert.Len(t, senders, 1) {
+		assert.Equal(t, "bob", senders[0].Sender)
+	}
+	senders = fees.Senders("cpu")
+	if assert.Len(t, senders, 1) {
+		assert.Equal(t, "alice", senders[0].Sender)
+		assert.Equal(t, SenderFees{Sender: "alice", Count: 2, Total: 300, Mean: 150, Min: 100, Max: 200}, senders[0])
+	}
+
+	_, err := json.Marshal(fees)
+	assert.Nil(t, err)
+}
@@ -0,0 +1,491 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+type filterFieldKind int
+
+const (
+	stringField filterFieldKind = iota
+	numberField
+	timeField
+	statusField
+)
+
+type filterField struct {
+	kind     filterFieldKind
+	getValue func(block Block, action Action) interface{}
+}
+
+var filterFields = map[string]filterField{
+	"name": {stringField, func(block Block, action Action) interface{} {
+		return action.Name()
+	}},
+	"sender": {stringField, func(block Block, action Action) interface{} {
+		return action.Sender()
+	}},
+	"receiver": {stringField, func(block Block, action Action) interface{} {
+		return action.Receiver()
+	}},
+	"status": {statusField, func(block Block, action Action) interface{} {
+		return action.Status()
+	}},
+	"block": {numberField, func(block Block, action Action) interface{} {
+		return block.Number()
+	}},
+	"time": {timeField, func(block Block, action Action) interface{} {
+		return block.Time()
+	}},
+}
+
+type filterExpr interface {
+	matches(block Block, action Action) bool
+}
+
+type andExpr struct {
+	left, right filterExpr
+}
+
+func (e andExpr) matches(block Block, action Action) bool {
+	return e.left.matches(block, action) && e.right.matches(block, action)
+}
+
+type orExpr struct {
+	left, right filterExpr
+}
+
+func (e orExpr) matches(block Block, action Action) bool {
+	return e.left.matches(block, action) || e.right.matches(block, action)
+}
+
+type notExpr struct {
+	expr filterExpr
+}
+
+func (e notExpr) matches(block Block, action Action) bool {
+	return !e.expr.matches(block, action)
+}
+
+type comparisonExpr struct {
+	field    filterField
+	operator string
+	values   []interface{}
+	regexp   *regexp.Regexp
+}
+
+func compareValues(a, b interface{}) int {
+	switch a := a.(type) {
+	case string:
+		return strings.Compare(a, b.(string))
+	case Status:
+		return int(a) - int(b.(Status))
+	case uint64:
+		b := b.(uint64)
+		if a < b {
+			return -1
+		} else if a > b {
+			return 1
+		}
+		return 0
+	case time.Time:
+		b := b.(time.Time)
+		if a.Before(b) {
+			return -1
+		} else if a.After(b) {
+			return 1
+		}
+		return 0
+	default:
+		panic(fmt.Errorf("cannot compare %v", a))
+	}
+}
+
+func (e comparisonExpr) matches(block Block, action Action) bool {
+	value := e.field.getValue(block, action)
+	switch e.operator {
+	case "=~":
+		return e.regexp.MatchString(value.(string))
+	case "!~":
+		return !e.regexp.MatchString(value.(string))
+	case "in":
+		for _, expected := range e.values {
+			if compareValues(value, expected) == 0 {
+				return true
+			}
+		}
+		return false
+	}
+	comparison := compareValues(value, e.values[0])
+	switch e.operator {
+	case "==":
+		return comparison == 0
+	case "!=":
+		return comparison != 0
+	case "<":
+		return comparison < 0
+	case "<=":
+		return comparison <= 0
+	case ">":
+		return comparison > 0
+	case ">=":
+		return comparison >= 0
+	default:
+		panic(fmt.Errorf("no such operator %s", e.operator))
+	}
+}
+
+type filterTokenKind int
+
+const (
+	identToken filterTokenKind = iota
+	stringToken
+	numberToken
+	operatorToken
+	endToken
+)
+
+type filterToken struct {
+	kind  filterTokenKind
+	value string
+}
+
+var filterOperators = []string{
+	"&&", "||", "==", "!=", "<=", ">=", "=~", "!~", "<", ">", "!", "(", ")", "[", "]", ",",
+}
+
+func tokenizeFilter(source string) ([]filterToken, error) {
+	var tokens []filterToken
+	runes := []rune(source)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '"' || r == '\'':
+			var value strings.Builder
+			j := i + 1
+			for ; j < len(runes) && runes[j] != r; j++ {
+				if runes[j] == '\\' && j+1 < len(runes) {
+					j++
+				}
+				value.WriteRune(runes[j])
+			}
+			if j == len(runes) {
+				return nil, fmt.Errorf("unterminated string at position %d", i)
+			}
+			tokens = append(tokens, filterToken{stringToken, value.String()})
+			i = j + 1
+		case unicode.IsDigit(r):
+			j := i
+			for j < len(runes) && unicode.IsDigit(runes[j]) {
+				j++
+			}
+			tokens = append(tokens, filterToken{numberToken, string(runes[i:j])})
+			i = j
+		case unicode.IsLetter(r) || r == '_':
+			j := i
+			for j < len(runes) && (unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j]) ||
+				runes[j] == '_' || runes[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, filterToken{identToken, string(runes[i:j])})
+			i = j
+		default:
+			matched := false
+			for _, operator := range filterOperators {
+				if strings.HasPrefix(string(runes[i:]), operator) {
+					tokens = append(tokens, filterToken{operatorToken, operator})
+					i += len([]rune(operator))
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, fmt.Errorf("unexpected character %q at position %d", r, i)
+			}
+		}
+	}
+	return append(tokens, filterToken{kind: endToken}), nil
+}
+
+type filterParser struct {
+	tokens   []filterToken
+	position int
+}
+
+func (p *filterParser) peek() filterToken {
+	return p.tokens[p.position]
+}
+
+func (p *filterParser) next() filterToken {
+	token := p.tokens[p.position]
+	if token.kind != endToken {
+		p.position++
+	}
+	return token
+}
+
+func (p *filterParser) accept(values ...string) bool {
+	token := p.peek()
+	if token.kind != operatorToken && token.kind != identToken {
+		return false
+	}
+	for _, value := range values {
+		if token.value == value {
+			p.next()
+			return true
+		}
+	}
+	return false
+}
+
+func (p *filterParser) expect(value string) error {
+	if !p.accept(value) {
+		return fmt.Errorf("expected %s, got %q", value, p.peek().value)
+	}
+	return nil
+}
+
+func (p *filterParser) parseOr() (filterExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("||", "or") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orExpr{left, right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (filterExpr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("&&", "and") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andExpr{left, right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseUnary() (filterExpr, error) {
+	if p.accept("!", "not") {
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notExpr{expr}, nil
+	}
+	if p.accept("(") {
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return expr, p.expect(")")
+	}
+	return p.parseComparison()
+}
+
+func parseFilterValue(token filterToken, kind filterFieldKind) (interface{}, error) {
+	switch kind {
+	case stringField:
+		if token.kind != stringToken {
+			return nil, fmt.Errorf("expected string, got %q", token.value)
+		}
+		return token.value, nil
+	case numberField:
+		if token.kind != numberToken {
+			return nil, fmt.Errorf("expected number, got %q", token.value)
+		}
+		return strconv.ParseUint(token.value, 10, 64)
+	case timeField:
+		if token.kind != stringToken {
+			return nil, fmt.Errorf("expected time string, got %q", token.value)
+		}
+		return ParseTime(token.value)
+	case statusField:
+		if token.kind != stringToken {
+			return nil, fmt.Errorf("expected status string, got %q", token.value)
+		}
+		return GetStatus(token.value)
+	default:
+		panic(fmt.Errorf("no such field kind %d", kind))
+	}
+}
+
+func (p *filterParser) parseComparison() (filterExpr, error) {
+	fieldToken := p.next()
+	if fieldToken.kind != identToken {
+		return nil, fmt.Errorf("expected field name, got %q", fieldToken.value)
+	}
+	field, ok := filterFields[fieldToken.value]
+	if strings.HasPrefix(fieldToken.value, dataFieldPrefix) {
+		path := strings.TrimPrefix(fieldToken.value, dataFieldPrefix)
+		field, ok = filterField{stringField, func(block Block, action Action) interface{} {
+			return GetDataField(action, path)
+		}}, path != ""
+	}
+	if !ok {
+		return nil, fmt.Errorf("unknown field %s", fieldToken.value)
+	}
+	operator := p.next()
+	expr := comparisonExpr{field: field, operator: operator.value}
+	switch operator.value {
+	case "==", "!=":
+	case "<", "<=", ">", ">=":
+		if field.kind == stringField || field.kind == statusField {
+			return nil, fmt.Errorf("operator %s cannot be used with %s", operator.value, fieldToken.value)
+		}
+	case "=~", "!~":
+		token := p.next()
+		if field.kind != stringField || token.kind != stringToken {
+			return nil, fmt.Errorf("operator %s expects a string field and a string pattern", operator.value)
+		}
+		compiled, err := regexp.Compile(token.value)
+		if err != nil {
+			return nil, err
+		}
+		expr.regexp = compiled
+		return expr, nil
+	case "in":
+		if err := p.expect("["); err != nil {
+			return nil, err
+		}
+		for !p.accept("]") {
+			if len(expr.values) > 0 {
+				if err := p.expect(","); err != nil {
+					return nil, err
+				}
+			}
+			value, err := parseFilterValue(p.next(), field.kind)
+			if err != nil {
+				return nil, err
+			}
+			expr.values = append(expr.values, value)
+		}
+		return expr, nil
+	default:
+		return nil, fmt.Errorf("expected operator after %s, got %q", fieldToken.value, operator.value)
+	}
+	value, err := parseFilterValue(p.next(), field.kind)
+	if err != nil {
+		return nil, err
+	}
+	expr.values = []interface{}{value}
+	return expr, nil
+}
+
+// Filter selects the actions passed to aggregators using expressions such as
+// name == "transfer" && receiver in ["eosio.token", "betdicetoken"]
+// Available fields are name, sender, receiver, status, block, time and data.<field> and
+// supported operators are ==, !=, <, <=, >, >=, =~, !~, in, &&, || and !
+type Filter struct {
+	source string
+	expr   filterExpr
+}
+
+func ParseFilter(source string) (*Filter, error) {
+	tokens, err := tokenizeFilter(source)
+	if err != nil {
+		return nil, err
+	}
+	parser := &filterParser{tokens: tokens}
+	expr, err := parser.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if token := parser.peek(); token.kind != endToken {
+		return nil, fmt.Errorf("unexpected %q at the end of filter", token.value)
+	}
+	return &Filter{source: source, expr: expr}, nil
+}
+
+func (f *Filter) Matches(block Block, action Action) bool {
+	return f == nil || f.expr.matches(block, action)
+}
+
+type filteredBlock struct {
+	Block
+	filter  *Filter
+	actions []Action
+}
+
+func (b *filteredBlock) ListActions() []Action {
+	return b.actions
+}
+
+// TransactionsCount returns the number of transactions with matching actions
+func (b *filteredBlock) TransactionsCount() int {
+	return len(b.ListTransactions())
+}
+
+// ListTransactions only returns the transactions with matching actions
+// and only keeps the matching actions of these transactions
+func (b *filteredBlock) ListTransactions() []Transaction {
+	var transactions []Transaction
+	for _, transaction := range b.Block.ListTransactions() {
+		var actions []Action
+		for _, action := range transaction.Actions {
+			if b.filter.expr.matches(b.Block, action) {
+				actions = append(actions, action)
+			}
+		}
+		if len(actions) > 0 {
+			transaction.Actions = actions
+			transactions = append(transactions, transaction)
+		}
+	}
+	return transactions
+}
+
+// Apply returns a block only containing the actions matching the filter
+// Blocks without matching actions are kept with no actions, so that block-level
+// aggregators such as block times or producers still see every block
+func (f *Filter) Apply(block Block) Block {
+	if f == nil {
+		return block
+	}
+	var actions []Action
+	for _, action := range block.ListActions() {
+		if f.expr.matches(block, action) {
+			actions = append(actions, action)
+		}
+	}
+	return &filteredBlock{Block: block, filter: f, actions: actions}
+}
+
+func (f *Filter) String() string {
+	return f.source
+}
+
+func (f *Filter) UnmarshalJSON(data []byte) error {
+	var source string
+	if err := json.Unmarshal(data, &source); err != nil {
+		return err
+	}
+	parsed, err := ParseFilter(source)
+	if err != nil {
+		return err
+	}
+	*f = *parsed
+	return nil
+}
+
+func (f *Filter) MarshalJSON() ([]byte, error) {
+	return json.Marshal(f.source)
+}
@@ -0,0 +1,75 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFilterErrors(t *testing.T) {
+	for _, source := range []string{
+		"",
+		"name",
+		"unknown == \"a\"",
+		"name == 1",
+		"block == \"a\"",
+		"name < \"a\"",
+		"name =~ \"(\"",
+		"name == \"a\" &&",
+		"(name == \"a\"",
+		"name in [\"a\" \"b\"]",
+		"name == \"a",
+	} {
+		_, err := ParseFilter(source)
+		assert.NotNil(t, err, source)
+	}
+}
+
+func TestFilterMatches(t *testing.T) {
+	block := newTestBlock()
+	cases := []struct {
+		source   string
+		expected int
+	}{
+		{`name == "transfer"`, 2},
+		{`name == "transfer" && receiver == "eosio.token"`, 1},
+		{`sender in ["alice", "carol"]`, 2},
+		{`receiver =~ "^betdice"`, 2},
+		{`!(receiver =~ "^betdice") or name == "bet"`, 2},
+		{`name != "transfer" || sender == 'bob'`, 2},
+		{`block >= 100 and block < 101`, 3},
+		{`block in [1, 2]`, 0},
+		{`time >= "2020-03-01" && time < "2020-03-01T12:00:00Z"`, 0},
+		{`not time < "2020-03-01T12:00:00Z"`, 3},
+	}
+	for _, c := range cases {
+		filter, err := ParseFilter(c.source)
+		assert.Nil(t, err, c.source)
+		count := 0
+		for _, action := range block.ListActions() {
+			if filter.Matches(block, action) {
+				count++
+			}
+		}
+		assert.Equal(t, c.expected, count, c.source)
+	}
+}
+
+func TestFilterApply(t *testing.T) {
+	block := newTestBlock()
+	var filter *Filter
+	assert.Equal(t, block, filter.Apply(block))
+
+	filter, err := ParseFilter(`sender == "alice"`)
+	assert.Nil(t, err)
+	filtered := filter.Apply(block)
+	assert.Len(t, filtered.ListActions(), 2)
+	assert.Equal(t, uint64(100), filtered.Number())
+
+	filter, err = ParseFilter(`sender == "carol"`)
+	assert.Nil(t, err)
+	filtered = filter.Apply(block)
+	assert.Len(t, filtered.ListActions(), 0)
+	assert.Len(t, filtered.ListTransactions(), 0)
+	assert.Equal(t, uint64(100), filtered.Number())
+}
@@ -0,0 +1,337 @@
+package core
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type GraphFormat int
+
+const (
+	CSVGraphFormat GraphFormat = iota
+	GraphMLFormat
+	GEXFFormat
+)
+
+func GetGraphFormat(name string) (GraphFormat, error) {
+	switch name {
+	case "csv":
+		return CSVGraphFormat, nil
+	case "graphml":
+		return GraphMLFormat, nil
+	case "gexf":
+		return GEXFFormat, nil
+	default:
+		return CSVGraphFormat, fmt.Errorf("no graph format %s", name)
+	}
+}
+
+// InferGraphFormat returns the format of a graph file from its extension,
+// e.g. graph.gexf or graph.csv.gz
+func InferGraphFormat(filePath string) (GraphFormat, error) {
+	extension := path.Ext(strings.TrimSuffix(filePath, ".gz"))
+	return GetGraphFormat(strings.TrimPrefix(extension, "."))
+}
+
+// Edge aggregates all the actions from Sender to Receiver, or only
+// the actions called Name if the graph is grouped by action name
+type Edge struct {
+	Sender     string
+	Receiver   string
+	Name       string
+	Count      uint64
+	FirstBlock uint64
+	LastBlock  uint64
+}
+
+type edgeKey struct {
+	sender, receiver, name string
+}
+
+// InteractionGraph is the weighted sender -> receiver graph of the actions
+type InteractionGraph struct {
+	edges  map[edgeKey]*Edge
+	byName bool
+}
+
+func NewInteractionGraph(byName bool) *InteractionGraph {
+	return &InteractionGraph{
+		edges:  make(map[edgeKey]*Edge),
+		byName: byName,
+	}
+}
+
+func (g *InteractionGraph) addEdge(edge Edge) {
+	key := edgeKey{edge.Sender, edge.Receiver, edge.Name}
+	existing, ok := g.edges[key]
+	if !ok {
+		g.edges[key] = &edge
+		return
+	}
+	existing.Count += edge.Count
+	if edge.FirstBlock < existing.FirstBlock {
+		existing.FirstBlock = edge.FirstBlock
+	}
+	if edge.LastBlock > existing.LastBlock {
+		existing.LastBlock = edge.LastBlock
+	}
+}
+
+func (g *InteractionGraph) AddBlock(block Block) {
+	for _, action := range block.ListActions() {
+		sender, receiver := action.Sender(), action.Receiver()
+		if sender == "" || receiver == "" {
+			continue
+		}
+		edge := Edge{
+			Sender:     sender,
+			Receiver:   receiver,
+			Count:      1,
+			FirstBlock: block.Number(),
+			LastBlock:  block.Number(),
+		}
+		if g.byName {
+			edge.Name = action.Name()
+		}
+		g.addEdge(edge)
+	}
+}
+
+func (g *InteractionGraph) Merge(other *InteractionGraph) {
+	for _, edge := range other.edges {
+		g.addEdge(*edge)
+	}
+}
+
+// SortedEdges returns the edges with a count of at least minWeight
+// sorted by decreasing count
+func (g *InteractionGraph) SortedEdges(minWeight uint64) []*Edge {
+	var edges []*Edge
+	for _, edge := range g.edges {
+		if edge.Count >= minWeight {
+			edges = append(edges, edge)
+		}
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		a, b := edges[i], edges[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if a.Sender != b.Sender {
+			return a.Sender < b.Sender
+		}
+		if a.Receiver != b.Receiver {
+			return a.Receiver < b.Receiver
+		}
+		return a.Name < b.Name
+	})
+	return edges
+}
+
+func (g *InteractionGraph) Result() interface{} {
+	return g.SortedEdges(0)
+}
+
+func (g *InteractionGraph) Write(writer io.Writer, format GraphFormat, minWeight uint64) error {
+	edges := g.SortedEdges(minWeight)
+	switch format {
+	case CSVGraphFormat:
+		return writeEdgesCSV(writer, edges)
+	case GraphMLFormat:
+		return writeGraphML(writer, edges)
+	case GEXFFormat:
+		return writeGEXF(writer, edges)
+	default:
+		return fmt.Errorf("no such graph format %d", format)
+	}
+}
+
+func listNodes(edges []*Edge) []string {
+	seen := make(map[string]bool)
+	var nodes []string
+	for _, edge := range edges {
+		for _, node := range []string{edge.Sender, edge.Receiver} {
+			if !seen[node] {
+				seen[node] = true
+				nodes = append(nodes, node)
+			}
+		}
+	}
+	sort.Strings(nodes)
+	return nodes
+}
+
+func writeEdgesCSV(writer io.Writer, edges []*Edge) error {
+	csvWriter := csv.NewWriter(writer)
+	headers := []string{"sender", "receiver", "name", "count", "first_block", "last_block"}
+	if err := csvWriter.Write(headers); err != nil {
+		return err
+	}
+	for _, edge := range edges {
+		row := []string{
+			edge.Sender,
+			edge.Receiver,
+			edge.Name,
+			strconv.FormatUint(edge.Count, 10),
+			strconv.FormatUint(edge.FirstBlock, 10),
+			strconv.FormatUint(edge.LastBlock, 10),
+		}
+		if err := csvWriter.Write(row); err != nil {
+			return err
+		}
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+type graphMLKey struct {
+	ID   string `xml:"id,attr"`
+	For  string `xml:"for,attr"`
+	Name string `xml:"attr.name,attr"`
+	Type string `xml:"attr.type,attr"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+type graphMLNode struct {
+	ID string `xml:"id,attr"`
+}
+
+type graphMLEdge struct {
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+type graphMLDocument struct {
+	XMLName xml.Name     `xml:"graphml"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   struct {
+		ID          string        `xml:"id,attr"`
+		EdgeDefault string        `xml:"edgedefault,attr"`
+		Nodes       []graphMLNode `xml:"node"`
+		Edges       []graphMLEdge `xml:"edge"`
+	} `xml:"graph"`
+}
+
+func writeGraphML(writer io.Writer, edges []*Edge) error {
+	document := graphMLDocument{
+		Xmlns: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []graphMLKey{
+			{ID: "name", For: "edge", Name: "name", Type: "string"},
+			{ID: "count", For: "edge", Name: "count", Type: "long"},
+			{ID: "first_block", For: "edge", Name: "first_block", Type: "long"},
+			{ID: "last_block", For: "edge", Name: "last_block", Type: "long"},
+		},
+	}
+	document.Graph.ID = "G"
+	document.Graph.EdgeDefault = "directed"
+	for _, node := range listNodes(edges) {
+		document.Graph.Nodes = append(document.Graph.Nodes, graphMLNode{ID: node})
+	}
+	for _, edge := range edges {
+		document.Graph.Edges = append(document.Graph.Edges, graphMLEdge{
+			Source: edge.Sender,
+			Target: edge.Receiver,
+			Data: []graphMLData{
+				{Key: "name", Value: edge.Name},
+				{Key: "count", Value: strconv.FormatUint(edge.Count, 10)},
+				{Key: "first_block", Value: strconv.FormatUint(edge.FirstBlock, 10)},
+				{Key: "last_block", Value: strconv.FormatUint(edge.LastBlock, 10)},
+			},
+		})
+	}
+	return writeXML(writer, document)
+}
+
+type gexfAttribute struct {
+	ID    string `xml:"id,attr"`
+	Title string `xml:"title,attr"`
+	Type  string `xml:"type,attr"`
+}
+
+type gexfAttValue struct {
+	For   string `xml:"for,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type gexfNode struct {
+	ID    string `xml:"id,attr"`
+	Label string `xml:"label,attr"`
+}
+
+type gexfEdge struct {
+	ID        string         `xml:"id,attr"`
+	Source    string         `xml:"source,attr"`
+	Target    string         `xml:"target,attr"`
+	Weight    uint64         `xml:"weight,attr"`
+	Label     string         `xml:"label,attr,omitempty"`
+	AttValues []gexfAttValue `xml:"attvalues>attvalue"`
+}
+
+type gexfDocument struct {
+	XMLName xml.Name `xml:"gexf"`
+	Xmlns   string   `xml:"xmlns,attr"`
+	Version string   `xml:"version,attr"`
+	Graph   struct {
+		DefaultEdgeType string `xml:"defaultedgetype,attr"`
+		Mode            string `xml:"mode,attr"`
+		Attributes      struct {
+			Class      string          `xml:"class,attr"`
+			Attributes []gexfAttribute `xml:"attribute"`
+		} `xml:"attributes"`
+		Nodes []gexfNode `xml:"nodes>node"`
+		Edges []gexfEdge `xml:"edges>edge"`
+	} `xml:"graph"`
+}
+
+func writeGEXF(writer io.Writer, edges []*Edge) error {
+	document := gexfDocument{Xmlns: "http://gexf.net/1.3", Version: "1.3"}
+	document.Graph.DefaultEdgeType = "directed"
+	document.Graph.Mode = "static"
+	document.Graph.Attributes.Class = "edge"
+	document.Graph.Attributes.Attributes = []gexfAttribute{
+		{ID: "first_block", Title: "first_block", Type: "long"},
+		{ID: "last_block", Title: "last_block", Type: "long"},
+	}
+	for _, node := range listNodes(edges) {
+		document.Graph.Nodes = append(document.Graph.Nodes, gexfNode{ID: node, Label: node})
+	}
+	for i, edge := range edges {
+		document.Graph.Edges = append(document.Graph.Edges, gexfEdge{
+			ID:     strconv.Itoa(i),
+			Source: edge.Sender,
+			Target: edge.Receiver,
+			Weight: edge.Count,
+			Label:  edge.Name,
+			AttValues: []gexfAttValue{
+				{For: "first_block", Value: strconv.FormatUint(edge.FirstBlock, 10)},
+				{For: "last_block", Value: strconv.FormatUint(edge.LastBlock, 10)},
+			},
+		})
+	}
+	return writeXML(writer, document)
+}
+
+func writeXML(writer io.Writer, document interface{}) error {
+	if _, err := io.WriteString(writer, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(writer)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(document); err != nil {
+		return err
+	}
+	_, err := io.WriteString(writer, "\n")
+	return err
+}
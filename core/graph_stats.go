@@ -0,0 +1,264 @@
+package core
+
+import (
+	"math"
+	"sort"
+)
+
+const (
+	pageRankDamping    = 0.85
+	pageRankIterations = 100
+	pageRankTolerance  = 1e-9
+)
+
+// DegreeCount is the number of nodes having the given degree
+type DegreeCount struct {
+	Degree int
+	Count  int
+}
+
+// ComponentSizeCount is the number of components having the given size
+type ComponentSizeCount struct {
+	Size  int
+	Count int
+}
+
+type NodeDegree struct {
+	Name   string
+	Degree int
+}
+
+type NodeScore struct {
+	Name  string
+	Score float64
+}
+
+// GraphStats summarizes the structure of an interaction graph
+// Degrees count distinct neighbors and components are weakly connected
+type GraphStats struct {
+	NodesCount            int
+	EdgesCount            int
+	Reciprocity           float64
+	InDegreeDistribution  []DegreeCount
+	OutDegreeDistribution []DegreeCount
+	TopInDegree           []NodeDegree
+	TopOutDegree          []NodeDegree
+	TopPageRank           []NodeScore
+	ComponentsCount       int
+	ComponentSizes        []ComponentSizeCount
+}
+
+type graphNode struct {
+	name        string
+	successors  map[int]uint64
+	inDegree    int
+	totalWeight uint64
+}
+
+type simpleGraph struct {
+	nodes   []*graphNode
+	indexes map[string]int
+}
+
+func (g *simpleGraph) nodeIndex(name string) int {
+	if index, ok := g.indexes[name]; ok {
+		return index
+	}
+	g.indexes[name] = len(g.nodes)
+	g.nodes = append(g.nodes, &graphNode{name: name, successors: make(map[int]uint64)})
+	return len(g.nodes) - 1
+}
+
+// simplify merges the edges of the different action names
+func (g *InteractionGraph) simplify() *simpleGraph {
+	graph := &simpleGraph{indexes: make(map[string]int)}
+	var names []string
+	for _, edge := range g.edges {
+		names = append(names, edge.Sender, edge.Receiver)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		graph.nodeIndex(name)
+	}
+	for _, edge := range g.edges {
+		sender := graph.nodes[graph.nodeIndex(edge.Sender)]
+		receiver := graph.nodeIndex(edge.Receiver)
+		if _, ok := sender.successors[receiver]; !ok {
+			graph.nodes[receiver].inDegree++
+		}
+		sender.successors[receiver] += edge.Count
+		sender.totalWeight += edge.Count
+	}
+	return graph
+}
+
+func (g *simpleGraph) edgesCount() int {
+	count := 0
+	for _, node := range g.nodes {
+		count += len(node.successors)
+	}
+	return count
+}
+
+// reciprocity is the fraction of edges, excluding self-loops,
+// for which the reverse edge also exists
+func (g *simpleGraph) reciprocity() float64 {
+	total, reciprocal := 0, 0
+	for i, node := range g.nodes {
+		for j := range node.successors {
+			if i == j {
+				continue
+			}
+			total++
+			if _, ok := g.nodes[j].successors[i]; ok {
+				reciprocal++
+			}
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(reciprocal) / float64(total)
+}
+
+func makeDegreeDistribution(degrees []int) []DegreeCount {
+	counts := make(map[int]int)
+	for _, degree := range degrees {
+		counts[degree]++
+	}
+	distribution := make([]DegreeCount, 0, len(counts))
+	for degree, count := range counts {
+		distribution = append(distribution, DegreeCount{Degree: degree, Count: count})
+	}
+	sort.Slice(distribution, func(i, j int) bool {
+		return distribution[i].Degree < distribution[j].Degree
+	})
+	return distribution
+}
+
+func topDegrees(nodes []*graphNode, degree func(*graphNode) int, top int) []NodeDegree {
+	result := make([]NodeDegree, len(nodes))
+	for i, node := range nodes {
+		result[i] = NodeDegree{Name: node.name, Degree: degree(node)}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Degree > result[j].Degree
+	})
+	if top > 0 && len(result) > top {
+		result = result[:top]
+	}
+	return result
+}
+
+// pageRank computes the weighted PageRank of the nodes, the rank of
+// nodes without successors being redistributed uniformly
+func (g *simpleGraph) pageRank() []float64 {
+	n := float64(len(g.nodes))
+	ranks := make([]float64, len(g.nodes))
+	for i := range ranks {
+		ranks[i] = 1 / n
+	}
+	for iteration := 0; iteration < pageRankIterations; iteration++ {
+		dangling := 0.0
+		for i, node := range g.nodes {
+			if node.totalWeight == 0 {
+				dangling += ranks[i]
+			}
+		}
+		next := make([]float64, len(g.nodes))
+		base := (1-pageRankDamping)/n + pageRankDamping*dangling/n
+		for i := range next {
+			next[i] = base
+		}
+		for i, node := range g.nodes {
+			for j, weight := range node.successors {
+				next[j] += pageRankDamping * ranks[i] * float64(weight) / float64(node.totalWeight)
+			}
+		}
+		delta := 0.0
+		for i := range ranks {
+			delta += math.Abs(next[i] - ranks[i])
+		}
+		ranks = next
+		if delta < pageRankTolerance {
+			break
+		}
+	}
+	return ranks
+}
+
+func (g *simpleGraph) componentSizes() []int {
+	parents := make([]int, len(g.nodes))
+	for i := range parents {
+		parents[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parents[i] != i {
+			parents[i] = find(parents[i])
+		}
+		return parents[i]
+	}
+	for i, node := range g.nodes {
+		for j := range node.successors {
+			parents[find(i)] = find(j)
+		}
+	}
+	sizes := make(map[int]int)
+	for i := range g.nodes {
+		sizes[find(i)]++
+	}
+	result := make([]int, 0, len(sizes))
+	for _, size := range sizes {
+		result = append(result, size)
+	}
+	return result
+}
+
+// ComputeStats returns the statistics of the graph, limiting
+// the top accounts to top entries (0 for unlimited)
+func (g *InteractionGraph) ComputeStats(top int) *GraphStats {
+	graph := g.simplify()
+	inDegrees := make([]int, len(graph.nodes))
+	outDegrees := make([]int, len(graph.nodes))
+	for i, node := range graph.nodes {
+		inDegrees[i] = node.inDegree
+		outDegrees[i] = len(node.successors)
+	}
+
+	ranks := graph.pageRank()
+	topPageRank := make([]NodeScore, len(graph.nodes))
+	for i, node := range graph.nodes {
+		topPageRank[i] = NodeScore{Name: node.name, Score: ranks[i]}
+	}
+	sort.SliceStable(topPageRank, func(i, j int) bool {
+		return topPageRank[i].Score > topPageRank[j].Score
+	})
+	if top > 0 && len(topPageRank) > top {
+		topPageRank = topPageRank[:top]
+	}
+
+	componentSizes := graph.componentSizes()
+	var componentSizeCounts []ComponentSizeCount
+	for _, count := range makeDegreeDistribution(componentSizes) {
+		componentSizeCounts = append(componentSizeCounts,
+			ComponentSizeCount{Size: count.Degree, Count: count.Count})
+	}
+
+	return &GraphStats{
+		NodesCount:            len(graph.nodes),
+		EdgesCount:            graph.edgesCount(),
+		Reciprocity:           graph.reciprocity(),
+		InDegreeDistribution:  makeDegreeDistribution(inDegrees),
+		OutDegreeDistribution: makeDegreeDistribution(outDegrees),
+		TopInDegree: topDegrees(graph.nodes, func(node *graphNode) int {
+			return node.inDegree
+		}, top),
+		TopOutDegree: topDegrees(graph.nodes, func(node *graphNode) int {
+			return len(node.successors)
+		}, top),
+		TopPageRank:     topPageRank,
+		ComponentsCount: len(componentSizes),
+		ComponentSizes:  componentSizeCounts,
+	}
+}
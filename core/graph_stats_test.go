@@ -0,0 +1,35 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComputeGraphStats(t *testing.T) {
+	graph := newTestGraph(true)
+	graph.AddBlock(newTestBlock(
+		testAction{"transfer", "eosio.token", "alice"},
+		testAction{"transfer", "dave", "erin"},
+	).at(130, testBlockTime))
+	stats := graph.ComputeStats(2)
+
+	assert.Equal(t, 7, stats.NodesCount)
+	assert.Equal(t, 5, stats.EdgesCount)
+	assert.InDelta(t, 0.4, stats.Reciprocity, 1e-9)
+	assert.Equal(t, []DegreeCount{{0, 2}, {1, 5}}, stats.InDegreeDistribution)
+	assert.Equal(t, []DegreeCount{{0, 3}, {1, 3}, {2, 1}}, stats.OutDegreeDistribution)
+	assert.Equal(t, []NodeDegree{{"alice", 2}, {"bob", 1}}, stats.TopOutDegree)
+	assert.Len(t, stats.TopInDegree, 2)
+	assert.Equal(t, 3, stats.ComponentsCount)
+	assert.Equal(t, []ComponentSizeCount{{2, 2}, {3, 1}}, stats.ComponentSizes)
+
+	allStats := graph.ComputeStats(0)
+	assert.Len(t, allStats.TopPageRank, 7)
+	total := 0.0
+	for _, score := range allStats.TopPageRank {
+		total += score.Score
+	}
+	assert.InDelta(t, 1.0, total, 1e-6)
+	assert.Equal(t, "alice", allStats.TopPageRank[0].Name)
+}
@@ -0,0 +1,82 @@
+package core
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestGraph(byName bool) *InteractionGraph {
+	graph := NewInteractionGraph(byName)
+	graph.AddBlock(newTestBlock())
+	other := newTestBlock()
+	other.number = 120
+	other.actions = []Action{
+		testAction{"transfer", "alice", "eosio.token"},
+		testAction{"issue", "alice", "eosio.token"},
+		testAction{"noop", "carol", ""},
+	}
+	graph.AddBlock(other)
+	return graph
+}
+
+func TestInferGraphFormat(t *testing.T) {
+	format, err := InferGraphFormat("graph.gexf")
+	assert.Nil(t, err)
+	assert.Equal(t, GEXFFormat, format)
+	format, err = InferGraphFormat("out/graph.csv.gz")
+	assert.Nil(t, err)
+	assert.Equal(t, CSVGraphFormat, format)
+	_, err = InferGraphFormat("graph.json")
+	assert.NotNil(t, err)
+}
+
+func TestInteractionGraph(t *testing.T) {
+	edges := newTestGraph(false).SortedEdges(0)
+	assert.Len(t, edges, 3)
+	assert.Equal(t, Edge{"alice", "eosio.token", "", 3, 100, 120}, *edges[0])
+	assert.Equal(t, "betdicegroup", edges[1].Receiver)
+
+	assert.Len(t, newTestGraph(false).SortedEdges(2), 1)
+
+	edges = newTestGraph(true).SortedEdges(0)
+	assert.Len(t, edges, 4)
+	assert.Equal(t, Edge{"alice", "eosio.token", "transfer", 2, 100, 120}, *edges[0])
+}
+
+func TestInteractionGraphMerge(t *testing.T) {
+	graph := NewInteractionGraph(false)
+	graph.AddBlock(newTestBlock())
+	graph.Merge(newTestGraph(false))
+	edges := graph.SortedEdges(0)
+	assert.Len(t, edges, 3)
+	assert.Equal(t, uint64(4), edges[0].Count)
+	assert.Equal(t, uint64(100), edges[0].FirstBlock)
+	assert.Equal(t, uint64(120), edges[0].LastBlock)
+}
+
+func TestInteractionGraphWrite(t *testing.T) {
+	graph := newTestGraph(true)
+
+	var buffer bytes.Buffer
+	assert.Nil(t, graph.Write(&buffer, CSVGraphFormat, 2))
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	assert.Equal(t, []string{
+		"sender,receiver,name,count,first_block,last_block",
+		"alice,eosio.token,transfer,2,100,120",
+	}, lines)
+
+	for _, format := range []GraphFormat{GraphMLFormat, GEXFFormat} {
+		buffer.Reset()
+		assert.Nil(t, graph.Write(&buffer, format, 1))
+		var document struct {
+			XMLName xml.Name
+		}
+		assert.Nil(t, xml.Unmarshal(buffer.Bytes(), &document))
+		assert.Contains(t, buffer.String(), `source="alice"`)
+		assert.Contains(t, buffer.String(), `target="betdicegroup"`)
+	}
+}
@@ -0,0 +1,86 @@
+package core
+
+import (
+	"time"
+)
+
+// UnknownDepth is the depth of actions from traces without nesting information
+const UnknownDepth = -1
+
+// InlineAction is implemented by actions parsed from execution traces,
+// such as EOS traces, which include inline actions and notifications
+type InlineAction interface {
+	// Depth returns 0 for top-level actions, the depth of the action which
+	// created it plus one for inline actions, or UnknownDepth if the trace
+	// does not say which action created it
+	Depth() int
+	// IsNotification returns true for copies of an action sent to notified accounts
+	IsNotification() bool
+}
+
+// InlineCounts counts top-level actions, inline actions and notifications
+// Actions which are not InlineAction are counted as top-level and actions
+// with an unknown depth are only counted in UnknownDepthCount
+// InlineRatio is the ratio of inline actions over top-level and inline actions
+type InlineCounts struct {
+	ActionsCount       uint64
+	TopLevelCount      uint64
+	InlineCount        uint64
+	NotificationsCount uint64
+	UnknownDepthCount  uint64
+	InlineRatio        float64
+	MaxDepth           int
+}
+
+func (c *InlineCounts) Add(action Action) {
+	c.ActionsCount++
+	inlineAction, ok := action.(InlineAction)
+	switch {
+	case !ok:
+		c.TopLevelCount++
+	case inlineAction.IsNotification():
+		c.NotificationsCount++
+	case inlineAction.Depth() == UnknownDepth:
+		c.UnknownDepthCount++
+	case inlineAction.Depth() > 0:
+		c.InlineCount++
+	default:
+		c.TopLevelCount++
+	}
+	if ok && inlineAction.Depth() > c.MaxDepth {
+		c.MaxDepth = inlineAction.Depth()
+	}
+	if executed := c.TopLevelCount + c.InlineCount; executed > 0 {
+		c.InlineRatio = float64(c.InlineCount) / float64(executed)
+	}
+}
+
+// InlineActionsOverTime counts top-level and inline actions for each period
+type InlineActionsOverTime struct {
+	Duration Duration
+	Total    *InlineCounts
+	OverTime map[time.Time]*InlineCounts
+}
+
+func NewInlineActionsOverTime(duration Duration) *InlineActionsOverTime {
+	return &InlineActionsOverTime{
+		Duration: duration,
+		Total:    &InlineCounts{},
+		OverTime: make(map[time.Time]*InlineCounts),
+	}
+}
+
+func (i *InlineActionsOverTime) AddBlock(block Block) {
+	group := i.Duration.Truncate(block.Time())
+	for _, action := range block.ListActions() {
+		if _, ok := i.OverTime[group]; !ok {
+			i.OverTime[group] = &InlineCounts{}
+		}
+		i.OverTime[group].Add(action)
+		i.Total.Add(action)
+	}
+}
+
+func (i *InlineActionsOverTime) Result() interface{} {
+	return i
+}
@@ -0,0 +1,41 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type inlineTestAction struct {
+	testAction
+	depth        int
+	notification bool
+}
+
+func (a inlineTestAction) Depth() int           { return a.depth }
+func (a inlineTestAction) IsNotification() bool { return a.notification }
+
+func TestInlineActionsOverTime(t *testing.T) {
+	inlineActions := NewInlineActionsOverTime(NewDuration(time.Hour))
+	block := newTestBlock()
+	block.actions = append(block.actions,
+		inlineTestAction{testAction{"transfer", "alice", "alice"}, 0, true},
+		inlineTestAction{testAction{"resolve", "dice", "dice"}, 1, false},
+		inlineTestAction{testAction{"transfer", "dice", "eosio.token"}, 2, false})
+	inlineActions.AddBlock(block)
+	later := newTestBlock()
+	later.time = later.time.Add(time.Hour)
+	inlineActions.AddBlock(later)
+
+	assert.Equal(t, uint64(9), inlineActions.Total.ActionsCount)
+	assert.Equal(t, uint64(6), inlineActions.Total.TopLevelCount)
+	assert.Equal(t, uint64(2), inlineActions.Total.InlineCount)
+	assert.Equal(t, uint64(1), inlineActions.Total.NotificationsCount)
+	assert.Equal(t, 2, inlineActions.Total.MaxDepth)
+	assert.InDelta(t, 0.25, inlineActions.Total.InlineRatio, 1e-9)
+
+	assert.Len(t, inlineActions.OverTime, 2)
+	assert.InDelta(t, 0.4, inlineActions.OverTime[block.time].InlineRatio, 1e-9)
+	assert.Equal(t, 0.0, inlineActions.OverTime[later.time].InlineRatio)
+}
@@ -0,0 +1,37 @@
+package core
+
+import (
+	"errors"
+	"io"
+
+	"github.com/xitongsys/parquet-go/source"
+)
+
+var errParquetWriteOnly = errors.New("parquet output is write-only")
+
+// parquetOutput adapts an io.Writer to the file interface of the Parquet
+// writer, which only writes the file sequentially
+type parquetOutput struct {
+	io.Writer
+}
+
+func (o *parquetOutput) Seek(offset int64, whence int) (int64, error) {
+	return 0, errParquetWriteOnly
+}
+
+func (o *parquetOutput) Read(p []byte) (int, error) {
+	return 0, errParquetWriteOnly
+}
+
+// Close does not close the underlying writer, which is owned by the caller
+func (o *parquetOutput) Close() error {
+	return nil
+}
+
+func (o *parquetOutput) Open(name string) (source.ParquetFile, error) {
+	return nil, errParquetWriteOnly
+}
+
+func (o *parquetOutput) Create(name string) (source.ParquetFile, error) {
+	return nil, errParquetWriteOnly
+}
@@ -0,0 +1,151 @@
+package core
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+)
+
+type producedBlock struct {
+	number       uint64
+	time         time.Time
+	producer     string
+	priority     int
+	slotDuration time.Duration
+}
+
+// ProducersStats are the statistics of the block producers during a period
+// MissedSlots counts all the slots detected as missed while MissedSlotsByProducer
+// only counts the slots which could be attributed to a producer
+type ProducersStats struct {
+	BlocksCount           uint64
+	Blocks                map[string]uint64
+	MissedSlots           uint64
+	MissedSlotsByProducer map[string]uint64
+	Concentration         ConcentrationMetrics
+}
+
+func newProducersStats() *ProducersStats {
+	return &ProducersStats{
+		Blocks:                make(map[string]uint64),
+		MissedSlotsByProducer: make(map[string]uint64),
+	}
+}
+
+func (s *ProducersStats) addMissedSlots(producer string, missed uint64) {
+	s.MissedSlots += missed
+	if producer != "" {
+		s.MissedSlotsByProducer[producer] += missed
+	}
+}
+
+func (s *ProducersStats) computeConcentration(topN []int) {
+	counts := make([]uint64, 0, len(s.Blocks))
+	for _, count := range s.Blocks {
+		counts = append(counts, count)
+	}
+	s.Concentration = ComputeConcentration(counts, topN)
+}
+
+// ProducersOverTime counts the blocks of each producer over time
+// Missed slots are detected using the priority of the blocks when available
+// or using the time between consecutive blocks for blockchains with fixed slots,
+// in which case they are attributed to the producer if it produced both blocks
+type ProducersOverTime struct {
+	Duration Duration
+	TopN     []int
+	blocks   map[uint64]producedBlock
+}
+
+func NewProducersOverTime(duration Duration, topN []int) *ProducersOverTime {
+	return &ProducersOverTime{
+		Duration: duration,
+		TopN:     topN,
+		blocks:   make(map[uint64]producedBlock),
+	}
+}
+
+func (p *ProducersOverTime) AddBlock(block Block) {
+	produced := producedBlock{
+		number:   block.Number(),
+		time:     block.Time(),
+		producer: block.Producer(),
+	}
+	if priorityBlock, ok := block.(PriorityBlock); ok {
+		produced.priority = priorityBlock.Priority()
+	}
+	if slotBlock, ok := block.(SlotBlock); ok {
+		produced.slotDuration = slotBlock.SlotDuration()
+	}
+	p.blocks[produced.number] = produced
+}
+
+func (p *ProducersOverTime) sortedBlocks() []producedBlock {
+	blocks := make([]producedBlock, 0, len(p.blocks))
+	for _, block := range p.blocks {
+		blocks = append(blocks, block)
+	}
+	sort.Slice(blocks, func(i, j int) bool {
+		return blocks[i].number < blocks[j].number
+	})
+	return blocks
+}
+
+func (p *ProducersOverTime) getStats(stats map[time.Time]*ProducersStats, t time.Time) *ProducersStats {
+	group := p.Duration.Truncate(t)
+	if _, ok := stats[group]; !ok {
+		stats[group] = newProducersStats()
+	}
+	return stats[group]
+}
+
+// Stats returns the statistics of the producers for each period and for the whole range
+func (p *ProducersOverTime) Stats() (map[time.Time]*ProducersStats, *ProducersStats) {
+	stats := make(map[time.Time]*ProducersStats)
+	total := newProducersStats()
+	blocks := p.sortedBlocks()
+	for i, block := range blocks {
+		groupStats := p.getStats(stats, block.time)
+		for _, s := range []*ProducersStats{groupStats, total} {
+			s.BlocksCount++
+			if block.producer != "" {
+				s.Blocks[block.producer]++
+			}
+			s.addMissedSlots("", uint64(block.priority))
+		}
+
+		if i == 0 || block.slotDuration == 0 {
+			continue
+		}
+		previous := blocks[i-1]
+		if previous.number+1 == block.number {
+			slots := block.time.Sub(previous.time) / block.slotDuration
+			if slots > 1 {
+				producer := ""
+				if previous.producer == block.producer {
+					producer = block.producer
+				}
+				groupStats.addMissedSlots(producer, uint64(slots-1))
+				total.addMissedSlots(producer, uint64(slots-1))
+			}
+		}
+	}
+	for _, s := range stats {
+		s.computeConcentration(p.TopN)
+	}
+	total.computeConcentration(p.TopN)
+	return stats, total
+}
+
+func (p *ProducersOverTime) MarshalJSON() ([]byte, error) {
+	stats, total := p.Stats()
+	return json.Marshal(map[string]interface{}{
+		"Duration":  p.Duration,
+		"Producers": stats,
+		"Total":     total,
+	})
+}
+
+func (p *ProducersOverTime) Result() interface{} {
+	return p
+}
@@ -0,0 +1,66 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type slotTestBlock struct {
+	testBlock
+}
+
+func (b *slotTestBlock) SlotDuration() time.Duration { return time.Second }
+
+type priorityTestBlock struct {
+	testBlock
+	priority int
+}
+
+func (b *priorityTestBlock) Priority() int { return b.priority }
+
+func TestProducersOverTimeSlots(t *testing.T) {
+	start := testBlockTime
+	producers := NewProducersOverTime(NewDuration(time.Hour), []int{1})
+	blocks := []struct {
+		number   uint64
+		offset   time.Duration
+		producer string
+	}{
+		{1, 0, "alice"},
+		{2, time.Second, "alice"},
+		{3, 3 * time.Second, "alice"},
+		{4, 6 * time.Second, "bob"},
+		{6, 8 * time.Second, "bob"},
+		{7, time.Hour, "bob"},
+	}
+	for _, block := range blocks {
+		slotBlock := newTestBlock().at(block.number, start.Add(block.offset))
+		slotBlock.producer = block.producer
+		producers.AddBlock(&slotTestBlock{*slotBlock})
+	}
+	stats, total := producers.Stats()
+	assert.Len(t, stats, 2)
+	assert.Equal(t, uint64(6), total.BlocksCount)
+	assert.Equal(t, map[string]uint64{"alice": 3, "bob": 3}, total.Blocks)
+	// 1 slot missed by alice, 2 between alice and bob, block 5 missing and a long gap by bob
+	assert.Equal(t, uint64(1+2+3591), total.MissedSlots)
+	assert.Equal(t, map[string]uint64{"alice": 1, "bob": 3591}, total.MissedSlotsByProducer)
+	assert.Equal(t, uint64(3), stats[start].MissedSlots)
+	assert.Equal(t, uint64(1), stats[start.Add(time.Hour)].BlocksCount)
+	assert.InDelta(t, 0.5, total.Concentration.TopShares[1], 1e-9)
+}
+
+func TestProducersOverTimePriority(t *testing.T) {
+	producers := NewProducersOverTime(NewDuration(time.Hour), nil)
+	for i, priority := range []int{0, 2, 1} {
+		block := newTestBlock()
+		block.number = uint64(i)
+		block.producer = "baker"
+		producers.AddBlock(&priorityTestBlock{*block, priority})
+	}
+	_, total := producers.Stats()
+	assert.Equal(t, uint64(3), total.MissedSlots)
+	assert.Empty(t, total.MissedSlotsByProducer)
+}
@@ -0,0 +1,423 @@
+package core
+
+import (
+	"container/heap"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/bits"
+	"sort"
+)
+
+func hashKey(key string) uint64 {
+	hasher := fnv.New64a()
+	hasher.Write([]byte(key))
+	// splitmix64 finalizer to spread the bits of FNV
+	h := hasher.Sum64()
+	h ^= h >> 30
+	h *= 0xbf58476d1ce4e5b9
+	h ^= h >> 27
+	h *= 0x94d049bb133111eb
+	h ^= h >> 31
+	return h
+}
+
+const (
+	minHyperLogLogPrecision = 4
+	maxHyperLogLogPrecision = 18
+)
+
+// HyperLogLog estimates the number of distinct keys added
+// with a relative standard error of 1.04 / sqrt(2^precision)
+type HyperLogLog struct {
+	registers []uint8
+	precision uint8
+}
+
+func NewHyperLogLog(precision uint8) (*HyperLogLog, error) {
+	if precision < minHyperLogLogPrecision || precision > maxHyperLogLogPrecision {
+		return nil, fmt.Errorf("precision must be between %d and %d",
+			minHyperLogLogPrecision, maxHyperLogLogPrecision)
+	}
+	return &HyperLogLog{
+		registers: make([]uint8, 1<<precision),
+		precision: precision,
+	}, nil
+}
+
+// NewHyperLogLogWithError returns the smallest HyperLogLog
+// with a relative standard error lower than relativeError
+func NewHyperLogLogWithError(relativeError float64) (*HyperLogLog, error) {
+	if relativeError <= 0 || relativeError >= 1 {
+		return nil, fmt.Errorf("relative error must be between 0 and 1")
+	}
+	precision := math.Ceil(math.Log2(math.Pow(1.04/relativeError, 2)))
+	return NewHyperLogLog(uint8(math.Max(precision, minHyperLogLogPrecision)))
+}
+
+func (h *HyperLogLog) RelativeError() float64 {
+	return 1.04 / math.Sqrt(float64(len(h.registers)))
+}
+
+func (h *HyperLogLog) Add(key string) {
+	hash := hashKey(key)
+	index := hash >> (64 - h.precision)
+	rank := uint8(bits.LeadingZeros64(hash<<h.precision|1<<(h.precision-1))) + 1
+	if rank > h.registers[index] {
+		h.registers[index] = rank
+	}
+}
+
+func (h *HyperLogLog) Count() uint64 {
+	m := float64(len(h.registers))
+	sum := 0.0
+	zeros := 0
+	for _, register := range h.registers {
+		sum += math.Pow(2, -float64(register))
+		if register == 0 {
+			zeros++
+		}
+	}
+	alpha := 0.7213 / (1 + 1.079/m)
+	switch len(h.registers) {
+	case 16:
+		alpha = 0.673
+	case 32:
+		alpha = 0.697
+	case 64:
+		alpha = 0.709
+	}
+	estimate := alpha * m * m / sum
+	if estimate <= 2.5*m && zeros > 0 {
+		estimate = m * math.Log(m/float64(zeros))
+	}
+	return uint64(math.Round(estimate))
+}
+
+func (h *HyperLogLog) Merge(other *HyperLogLog) error {
+	if h.precision != other.precision {
+		return fmt.Errorf("cannot merge HyperLogLog with precisions %d and %d",
+			h.precision, other.precision)
+	}
+	for i, register := range other.registers {
+		if register > h.registers[i] {
+			h.registers[i] = register
+		}
+	}
+	return nil
+}
+
+// CountMinSketch estimates the count of each key, overestimating it
+// by at most epsilon times the total count with probability 1 - delta
+type CountMinSketch struct {
+	counts     [][]uint64
+	width      uint64
+	TotalCount uint64
+}
+
+func NewCountMinSketch(epsilon, delta float64) (*CountMinSketch, error) {
+	if epsilon <= 0 || epsilon >= 1 || delta <= 0 || delta >= 1 {
+		return nil, fmt.Errorf("epsilon and delta must be between 0 and 1")
+	}
+	width := uint64(math.Ceil(math.E / epsilon))
+	depth := int(math.Ceil(math.Log(1 / delta)))
+	counts := make([][]uint64, depth)
+	for i := range counts {
+		counts[i] = make([]uint64, width)
+	}
+	return &CountMinSketch{counts: counts, width: width}, nil
+}
+
+func (s *CountMinSketch) indexes(key string) []uint64 {
+	hash := hashKey(key)
+	h1, h2 := hash&0xffffffff, hash>>32
+	indexes := make([]uint64, len(s.counts))
+	for i := range indexes {
+		indexes[i] = (h1 + uint64(i)*h2) % s.width
+	}
+	return indexes
+}
+
+func (s *CountMinSketch) Add(key string, count uint64) {
+	s.TotalCount += count
+	for row, index := range s.indexes(key) {
+		s.counts[row][index] += count
+	}
+}
+
+func (s *CountMinSketch) Estimate(key string) uint64 {
+	estimate := uint64(math.MaxUint64)
+	for row, index := range s.indexes(key) {
+		if s.counts[row][index] < estimate {
+			estimate = s.counts[row][index]
+		}
+	}
+	return estimate
+}
+
+func (s *CountMinSketch) Merge(other *CountMinSketch) error {
+	if s.width != other.width || len(s.counts) != len(other.counts) {
+		return fmt.Errorf("cannot merge count-min sketches of different sizes")
+	}
+	s.TotalCount += other.TotalCount
+	for row := range s.counts {
+		for i, count := range other.counts[row] {
+			s.counts[row][i] += count
+		}
+	}
+	return nil
+}
+
+// SpaceSavingCounter is the estimated count of a key, overestimated by at most Error
+type SpaceSavingCounter struct {
+	Name  string
+	Count uint64
+	Error uint64
+	index int
+}
+
+type spaceSavingHeap []*SpaceSavingCounter
+
+func (h spaceSavingHeap) Len() int           { return len(h) }
+func (h spaceSavingHeap) Less(i, j int) bool { return h[i].Count < h[j].Count }
+func (h spaceSavingHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *spaceSavingHeap) Push(x interface{}) {
+	counter := x.(*SpaceSavingCounter)
+	counter.index = len(*h)
+	*h = append(*h, counter)
+}
+
+func (h *spaceSavingHeap) Pop() interface{} {
+	old := *h
+	counter := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return counter
+}
+
+// SpaceSaving keeps track of the (approximately) k most frequent keys
+// Any key with a count greater than TotalCount / k is guaranteed to be tracked
+type SpaceSaving struct {
+	counters   map[string]*SpaceSavingCounter
+	heap       spaceSavingHeap
+	k          int
+	TotalCount uint64
+}
+
+func NewSpaceSaving(k int) (*SpaceSaving, error) {
+	if k <= 0 {
+		return nil, fmt.Errorf("k must be positive")
+	}
+	return &SpaceSaving{
+		counters: make(map[string]*SpaceSavingCounter),
+		k:        k,
+	}, nil
+}
+
+func (s *SpaceSaving) minCount() uint64 {
+	if len(s.heap) < s.k {
+		return 0
+	}
+	return s.heap[0].Count
+}
+
+func (s *SpaceSaving) Add(key string, count uint64) {
+	s.TotalCount += count
+	if counter, ok := s.counters[key]; ok {
+		counter.Count += count
+		heap.Fix(&s.heap, counter.index)
+		return
+	}
+	if len(s.heap) < s.k {
+		counter := &SpaceSavingCounter{Name: key, Count: count}
+		s.counters[key] = counter
+		heap.Push(&s.heap, counter)
+		return
+	}
+	counter := s.heap[0]
+	delete(s.counters, counter.Name)
+	counter.Name = key
+	counter.Error = counter.Count
+	counter.Count += count
+	s.counters[key] = counter
+	heap.Fix(&s.heap, 0)
+}
+
+// estimate returns the counter of key or, if the key is not tracked,
+// a counter with the minimum count as an upper bound
+func (s *SpaceSaving) estimate(key string) SpaceSavingCounter {
+	if counter, ok := s.counters[key]; ok {
+		return *counter
+	}
+	return SpaceSavingCounter{Name: key, Count: s.minCount(), Error: s.minCount()}
+}
+
+// Merge combines the counters of both summaries, keeping the k largest
+func (s *SpaceSaving) Merge(other *SpaceSaving) {
+	keys := make(map[string]bool)
+	for key := range s.counters {
+		keys[key] = true
+	}
+	for key := range other.counters {
+		keys[key] = true
+	}
+	counters := make([]*SpaceSavingCounter, 0, len(keys))
+	for key := range keys {
+		selfCounter, otherCounter := s.estimate(key), other.estimate(key)
+		counters = append(counters, &SpaceSavingCounter{
+			Name:  key,
+			Count: selfCounter.Count + otherCounter.Count,
+			Error: selfCounter.Error + otherCounter.Error,
+		})
+	}
+	sortSpaceSavingCounters(counters)
+	if len(counters) > s.k {
+		counters = counters[:s.k]
+	}
+	s.counters = make(map[string]*SpaceSavingCounter)
+	s.heap = nil
+	for _, counter := range counters {
+		s.counters[counter.Name] = counter
+		heap.Push(&s.heap, counter)
+	}
+	s.TotalCount += other.TotalCount
+}
+
+func sortSpaceSavingCounters(counters []*SpaceSavingCounter) {
+	sort.Slice(counters, func(i, j int) bool {
+		if counters[i].Count == counters[j].Count {
+			return counters[i].Name < counters[j].Name
+		}
+		return counters[i].Count > counters[j].Count
+	})
+}
+
+// Top returns the tracked keys sorted by decreasing count
+func (s *SpaceSaving) Top() []*SpaceSavingCounter {
+	counters := make([]*SpaceSavingCounter, len(s.heap))
+	copy(counters, s.heap)
+	sortSpaceSavingCounters(counters)
+	return counters
+}
+
+type centroid struct {
+	mean   float64
+	weight float64
+}
+
+// TDigest estimates the quantiles of a stream of values, with a better
+// accuracy for extreme quantiles. Higher compressions are more accurate
+// but keep more centroids
+type TDigest struct {
+	compression float64
+	centroids   []centroid
+	buffer      []centroid
+	count       float64
+	min         float64
+	max         float64
+}
+
+func NewTDigest(compression float64) (*TDigest, error) {
+	if compression < 10 {
+		return nil, fmt.Errorf("compression must be at least 10")
+	}
+	return &TDigest{compression: compression}, nil
+}
+
+func (t *TDigest) Add(value float64) {
+	t.add(centroid{mean: value, weight: 1})
+}
+
+func (t *TDigest) add(c centroid) {
+	if t.count == 0 || c.mean < t.min {
+		t.min = c.mean
+	}
+	if t.count == 0 || c.mean > t.max {
+		t.max = c.mean
+	}
+	t.count += c.weight
+	t.buffer = append(t.buffer, c)
+	if float64(len(t.buffer)) > 5*t.compression {
+		t.compress()
+	}
+}
+
+// compress merges neighboring centroids as long as their weight stays
+// below 4 * count * q * (1 - q) / compression
+func (t *TDigest) compress() {
+	if len(t.buffer) == 0 {
+		return
+	}
+	all := make([]centroid, 0, len(t.centroids)+len(t.buffer))
+	all = append(append(all, t.centroids...), t.buffer...)
+	sort.Slice(all, func(i, j int) bool { return all[i].mean < all[j].mean })
+	merged := []centroid{all[0]}
+	cumulative := 0.0
+	for _, c := range all[1:] {
+		last := &merged[len(merged)-1]
+		q := (cumulative + (last.weight+c.weight)/2) / t.count
+		limit := math.Max(4*t.count*q*(1-q)/t.compression, 1)
+		if last.weight+c.weight <= limit {
+			last.mean += (c.mean - last.mean) * c.weight / (last.weight + c.weight)
+			last.weight += c.weight
+		} else {
+			cumulative += last.weight
+			merged = append(merged, c)
+		}
+	}
+	t.centroids = merged
+	t.buffer = nil
+}
+
+func (t *TDigest) Count() uint64 {
+	return uint64(t.count)
+}
+
+func (t *TDigest) Min() float64 {
+	return t.min
+}
+
+func (t *TDigest) Max() float64 {
+	return t.max
+}
+
+// Quantile returns the estimated value at quantile q, between 0 and 1
+func (t *TDigest) Quantile(q float64) float64 {
+	t.compress()
+	if t.count == 0 {
+		return 0
+	}
+	if q <= 0 {
+		return t.min
+	}
+	if q >= 1 {
+		return t.max
+	}
+	target := q * t.count
+	previousMean, previousPosition := t.min, 0.0
+	cumulative := 0.0
+	for _, c := range t.centroids {
+		position := cumulative + c.weight/2
+		if target < position {
+			ratio := (target - previousPosition) / (position - previousPosition)
+			return previousMean + ratio*(c.mean-previousMean)
+		}
+		previousMean, previousPosition = c.mean, position
+		cumulative += c.weight
+	}
+	ratio := (target - previousPosition) / (t.count - previousPosition)
+	return previousMean + ratio*(t.max-previousMean)
+}
+
+func (t *TDigest) Merge(other *TDigest) {
+	for _, centroids := range [][]centroid{other.centroids, other.buffer} {
+		for _, c := range centroids {
+			t.add(c)
+		}
+	}
+	t.compress()
+}
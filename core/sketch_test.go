@@ -0,0 +1,107 @@
+package core
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHyperLogLog(t *testing.T) {
+	_, err := NewHyperLogLog(2)
+	assert.NotNil(t, err)
+
+	sketch, err := NewHyperLogLogWithError(0.01)
+	assert.Nil(t, err)
+	assert.LessOrEqual(t, sketch.RelativeError(), 0.01)
+	assert.Equal(t, uint64(0), sketch.Count())
+
+	other, _ := NewHyperLogLogWithError(0.01)
+	for i := 0; i < 100000; i++ {
+		sketch.Add(fmt.Sprintf("account-%d", i))
+		other.Add(fmt.Sprintf("account-%d", i+50000))
+	}
+	assert.InDelta(t, 100000, sketch.Count(), 3*0.01*100000)
+
+	assert.Nil(t, sketch.Merge(other))
+	assert.InDelta(t, 150000, sketch.Count(), 3*0.01*150000)
+
+	small, _ := NewHyperLogLog(10)
+	assert.NotNil(t, sketch.Merge(small))
+}
+
+func TestCountMinSketch(t *testing.T) {
+	sketch, err := NewCountMinSketch(0.001, 0.01)
+	assert.Nil(t, err)
+	other, _ := NewCountMinSketch(0.001, 0.01)
+	for i := 0; i < 10000; i++ {
+		sketch.Add(fmt.Sprintf("account-%d", i%100), 1)
+		other.Add(fmt.Sprintf("account-%d", i%10), 1)
+	}
+	assert.GreaterOrEqual(t, sketch.Estimate("account-1"), uint64(100))
+	assert.LessOrEqual(t, sketch.Estimate("account-1"), uint64(100+0.001*10000))
+	assert.Nil(t, sketch.Merge(other))
+	assert.GreaterOrEqual(t, sketch.Estimate("account-1"), uint64(1100))
+	assert.Equal(t, uint64(20000), sketch.TotalCount)
+}
+
+func TestSpaceSaving(t *testing.T) {
+	summary, err := NewSpaceSaving(10)
+	assert.Nil(t, err)
+	other, _ := NewSpaceSaving(10)
+	// account-i appears 1000 / (i + 1) times
+	for i := 0; i < 1000; i++ {
+		for j := 0; j*(i+1) < 1000; j++ {
+			summary.Add(fmt.Sprintf("account-%d", i), 1)
+			other.Add(fmt.Sprintf("account-%d", i), 1)
+		}
+	}
+	top := summary.Top()
+	assert.Len(t, top, 10)
+	assert.Equal(t, "account-0", top[0].Name)
+	assert.Equal(t, uint64(1000), top[0].Count-top[0].Error)
+	for _, counter := range top {
+		assert.LessOrEqual(t, counter.Error, summary.TotalCount/10)
+	}
+
+	summary.Merge(other)
+	top = summary.Top()
+	assert.Len(t, top, 10)
+	assert.Equal(t, "account-0", top[0].Name)
+	assert.GreaterOrEqual(t, top[0].Count, uint64(2000))
+	assert.LessOrEqual(t, top[0].Count-top[0].Error, uint64(2000))
+	assert.Equal(t, 2*other.TotalCount, summary.TotalCount)
+}
+
+func TestTDigest(t *testing.T) {
+	_, err := NewTDigest(1)
+	assert.NotNil(t, err)
+
+	first, err := NewTDigest(100)
+	assert.Nil(t, err)
+	second, _ := NewTDigest(100)
+	for i := 1; i <= 10000; i++ {
+		if i%2 == 0 {
+			first.Add(float64(i))
+		} else {
+			second.Add(float64(i))
+		}
+	}
+	assert.InDelta(t, 5000, first.Quantile(0.5), 100)
+	first.Merge(second)
+	assert.Equal(t, uint64(10000), first.Count())
+	assert.Equal(t, 1.0, first.Min())
+	assert.Equal(t, 10000.0, first.Max())
+	assert.Equal(t, 1.0, first.Quantile(0))
+	assert.Equal(t, 10000.0, first.Quantile(1))
+	for _, q := range []float64{0.01, 0.1, 0.5, 0.9, 0.99} {
+		assert.InDelta(t, q*10000, first.Quantile(q), 50, "quantile %f", q)
+	}
+	assert.Less(t, len(first.centroids), 1000)
+
+	constant, _ := NewTDigest(100)
+	for i := 0; i < 100; i++ {
+		constant.Add(7)
+	}
+	assert.Equal(t, 7.0, constant.Quantile(0.99))
+}
@@ -0,0 +1,103 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Status is the execution status of the transaction containing an action
+type Status int
+
+const (
+	// StatusUnknown is used when the data does not contain the status
+	StatusUnknown Status = iota
+	StatusSuccess
+	StatusFailure
+)
+
+// GetStatus parses the name of a status, e.g. in status filters
+func GetStatus(name string) (Status, error) {
+	switch name {
+	case "unknown":
+		return StatusUnknown, nil
+	case "success":
+		return StatusSuccess, nil
+	case "failure":
+		return StatusFailure, nil
+	default:
+		return StatusUnknown, fmt.Errorf("no status %s", name)
+	}
+}
+
+func (s Status) String() string {
+	switch s {
+	case StatusUnknown:
+		return "unknown"
+	case StatusSuccess:
+		return "success"
+	case StatusFailure:
+		return "failure"
+	default:
+		panic(fmt.Errorf("no such status %d", s))
+	}
+}
+
+func (s Status) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+// StatusCounts counts the actions of each status
+// FailureRate only considers the actions with a known status
+type StatusCounts struct {
+	ActionsCount uint64
+	SuccessCount uint64
+	FailureCount uint64
+	UnknownCount uint64
+	FailureRate  float64
+}
+
+func (c *StatusCounts) Add(status Status) {
+	c.ActionsCount++
+	switch status {
+	case StatusSuccess:
+		c.SuccessCount++
+	case StatusFailure:
+		c.FailureCount++
+	default:
+		c.UnknownCount++
+	}
+	if known := c.SuccessCount + c.FailureCount; known > 0 {
+		c.FailureRate = float64(c.FailureCount) / float64(known)
+	}
+}
+
+// FailureRateOverTime counts successful and failed actions for each period
+type FailureRateOverTime struct {
+	Duration Duration
+	Total    *StatusCounts
+	OverTime map[time.Time]*StatusCounts
+}
+
+func NewFailureRateOverTime(duration Duration) *FailureRateOverTime {
+	return &FailureRateOverTime{
+		Duration: duration,
+		Total:    &StatusCounts{},
+		OverTime: make(map[time.Time]*StatusCounts),
+	}
+}
+
+func (f *FailureRateOverTime) AddBlock(block Block) {
+	group := f.Duration.Truncate(block.Time())
+	for _, action := range block.ListActions() {
+		if _, ok := f.OverTime[group]; !ok {
+			f.OverTime[group] = &StatusCounts{}
+		}
+		f.OverTime[group].Add(action.Status())
+		f.Total.Add(action.Status())
+	}
+}
+
+func (f *FailureRateOverTime) Result() interface{} {
+	return f
+}
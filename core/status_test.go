@@ -0,0 +1,73 @@
+package core
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type statusTestAction struct {
+	testAction
+	status Status
+}
+
+func (a statusTestAction) Status() Status { return a.status }
+
+var statusTestActions = []Action{
+	statusTestAction{testAction{"transfer", "alice", "eosio.token"}, StatusFailure},
+	statusTestAction{testAction{"transfer", "bob", "eosio.token"}, StatusUnknown},
+}
+
+func TestGetStatus(t *testing.T) {
+	for _, status := range []Status{StatusUnknown, StatusSuccess, StatusFailure} {
+		parsed, err := GetStatus(status.String())
+		assert.Nil(t, err)
+		assert.Equal(t, status, parsed)
+	}
+	_, err := GetStatus("pending")
+	assert.NotNil(t, err)
+
+	marshaled, err := json.Marshal(StatusFailure)
+	assert.Nil(t, err)
+	assert.Equal(t, `"failure"`, string(marshaled))
+}
+
+func TestStatusFilterAndProperty(t *testing.T) {
+	block := newTestBlock(append(defaultTestActions(), statusTestActions...)...)
+	filter, err := ParseFilter(`status == "failure"`)
+	assert.Nil(t, err)
+	assert.Len(t, filter.Apply(block).ListActions(), 1)
+	filter, err = ParseFilter(`status in ["failure", "unknown"]`)
+	assert.Nil(t, err)
+	assert.Len(t, filter.Apply(block).ListActions(), 2)
+	_, err = ParseFilter(`status == "failed"`)
+	assert.EqualError(t, err, "no status failed")
+	_, err = ParseFilter(`status > "failure"`)
+	assert.NotNil(t, err)
+
+	property, err := GetActionProperty("status", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "success", property.Get(block, block.actions[0]))
+	assert.Equal(t, "unknown", property.Get(block, block.actions[4]))
+}
+
+func TestFailureRateOverTime(t *testing.T) {
+	failureRate := NewFailureRateOverTime(NewDuration(time.Hour))
+	block := newTestBlock(append(defaultTestActions(), statusTestActions...)...)
+	failureRate.AddBlock(block)
+	later := newTestBlock()
+	later.time = later.time.Add(time.Hour)
+	failureRate.AddBlock(later)
+
+	assert.Equal(t, uint64(8), failureRate.Total.ActionsCount)
+	assert.Equal(t, uint64(6), failureRate.Total.SuccessCount)
+	assert.Equal(t, uint64(1), failureRate.Total.FailureCount)
+	assert.Equal(t, uint64(1), failureRate.Total.UnknownCount)
+	assert.InDelta(t, 1.0/7.0, failureRate.Total.FailureRate, 1e-9)
+
+	assert.Len(t, failureRate.OverTime, 2)
+	assert.InDelta(t, 0.25, failureRate.OverTime[block.time].FailureRate, 1e-9)
+	assert.Equal(t, 0.0, failureRate.OverTime[later.time].FailureRate)
+}
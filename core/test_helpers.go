@@ -14,8 +14,13 @@ const (
 	XRPMissingLedgersFilename     string = "xrp-missing-block.jsonl"
 	XRPDuplicatedLedgersFilename  string = "xrp-duplicated.jsonl"
 
-	EOSValidBlocksFilename   string = "eos-blocks-120893532--120893631.jsonl.gz"
-	TezosValidBlocksFilename string = "tezos-blocks.jsonl"
+	EOSValidBlocksFilename     string = "eos-blocks-120893532--120893631.jsonl.gz"
+	TezosValidBlocksFilename   string = "tezos-blocks.jsonl"
+	EOSABIsDirectory           string = "abis"
+	EOSTracesFilename          string = "eos-traces.jsonl"
+	EOSTraceAPIBlockFilename   string = "eos-trace-api-block.jsonl"
+	CategoriesFilename         string = "categories.json"
+	CategoriesOverrideFilename string = "categories-override.json"
 )
 
 func GetFixturesPath() string {
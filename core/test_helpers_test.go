@@ -0,0 +1,66 @@
+package core
+
+import (
+	"time"
+)
+
+type testAction struct {
+	name, sender, receiver string
+}
+
+func (a testAction) Name() string     { return a.name }
+func (a testAction) Sender() string   { return a.sender }
+func (a testAction) Receiver() string { return a.receiver }
+func (a testAction) Status() Status   { return StatusSuccess }
+
+type testBlock struct {
+	number       uint64
+	time         time.Time
+	actions      []Action
+	producer     string
+	transactions []Transaction
+}
+
+func (b *testBlock) Number() uint64         { return b.number }
+func (b *testBlock) Time() time.Time        { return b.time }
+func (b *testBlock) TransactionsCount() int { return len(b.actions) }
+func (b *testBlock) ListActions() []Action  { return b.actions }
+func (b *testBlock) Producer() string       { return b.producer }
+
+// ListTransactions returns a transaction per action unless transactions are set
+func (b *testBlock) ListTransactions() []Transaction {
+	if b.transactions != nil {
+		return b.transactions
+	}
+	transactions := make([]Transaction, len(b.actions))
+	for i, action := range b.actions {
+		transactions[i] = Transaction{Index: i, Signer: action.Sender(), Actions: []Action{action}}
+	}
+	return transactions
+}
+
+// at sets the number and time of the block
+func (b *testBlock) at(number uint64, blockTime time.Time) *testBlock {
+	b.number, b.time = number, blockTime
+	return b
+}
+
+var testBlockTime = time.Date(2020, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// defaultTestActions are the actions of test blocks created without actions
+func defaultTestActions() []Action {
+	return []Action{
+		testAction{"transfer", "alice", "eosio.token"},
+		testAction{"transfer", "bob", "betdicetoken"},
+		testAction{"bet", "alice", "betdicegroup"},
+	}
+}
+
+// newTestBlock returns block 100 at testBlockTime with the given actions
+// or with defaultTestActions if no action is given
+func newTestBlock(actions ...Action) *testBlock {
+	if len(actions) == 0 {
+		actions = defaultTestActions()
+	}
+	return &testBlock{number: 100, time: testBlockTime, actions: actions}
+}
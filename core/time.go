@@ -0,0 +1,163 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+var timeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	"2006-01",
+}
+
+// ParseTime parses a date or timestamp given by the user, e.g. 2020-03-01 or
+// 2020-03-01T12:00:00Z. Timestamps without timezone are interpreted as UTC
+func ParseTime(value string) (time.Time, error) {
+	for _, layout := range timeLayouts {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return parsed.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("could not parse time %s", value)
+}
+
+type Timestamp struct {
+	time.Time
+}
+
+func (t *Timestamp) UnmarshalJSON(b []byte) (err error) {
+	var rawTime string
+	if err = json.Unmarshal(b, &rawTime); err != nil {
+		return err
+	}
+	t.Time, err = ParseTime(rawTime)
+	return err
+}
+
+// TimeRange is a [From, To) time interval where a zero bound means unbounded
+type TimeRange struct {
+	From time.Time
+	To   time.Time
+}
+
+func NewTimeRange(from, to time.Time) TimeRange {
+	return TimeRange{From: from, To: to}
+}
+
+func (r TimeRange) IsZero() bool {
+	return r.From.IsZero() && r.To.IsZero()
+}
+
+func (r TimeRange) Contains(t time.Time) bool {
+	return (r.From.IsZero() || !t.Before(r.From)) &&
+		(r.To.IsZero() || t.Before(r.To))
+}
+
+// Overlaps returns true if the [from, to) interval has a common part with the range
+func (r TimeRange) Overlaps(from, to time.Time) bool {
+	return (r.From.IsZero() || to.After(r.From)) &&
+		(r.To.IsZero() || from.Before(r.To))
+}
+
+type TimePartition int
+
+const (
+	DailyPartition TimePartition = iota
+	MonthlyPartition
+)
+
+func GetTimePartition(name string) (TimePartition, error) {
+	switch name {
+	case "day":
+		return DailyPartition, nil
+	case "month":
+		return MonthlyPartition, nil
+	default:
+		return MonthlyPartition, fmt.Errorf("no time partition %s", name)
+	}
+}
+
+func (p TimePartition) String() string {
+	switch p {
+	case DailyPartition:
+		return "day"
+	case MonthlyPartition:
+		return "month"
+	default:
+		panic(fmt.Errorf("no such time partition"))
+	}
+}
+
+func (p TimePartition) layout() string {
+	switch p {
+	case DailyPartition:
+		return "2006-01-02"
+	case MonthlyPartition:
+		return "2006-01"
+	default:
+		panic(fmt.Errorf("no such time partition"))
+	}
+}
+
+// Truncate returns the start of the partition containing t
+func (p TimePartition) Truncate(t time.Time) time.Time {
+	t = t.UTC()
+	switch p {
+	case DailyPartition:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	case MonthlyPartition:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		panic(fmt.Errorf("no such time partition"))
+	}
+}
+
+// Next returns the start of the partition following the one containing t
+func (p TimePartition) Next(t time.Time) time.Time {
+	switch p {
+	case DailyPartition:
+		return p.Truncate(t).AddDate(0, 0, 1)
+	case MonthlyPartition:
+		return p.Truncate(t).AddDate(0, 1, 0)
+	default:
+		panic(fmt.Errorf("no such time partition"))
+	}
+}
+
+// MakeTimePartitionFilename returns the name of the file containing the blocks
+// of the partition of t, e.g. eos.jsonl.gz becomes eos-2020-03.jsonl.gz
+// Only the file name is changed, so dots in the directory are kept as is
+func MakeTimePartitionFilename(filePath string, t time.Time, partition TimePartition) string {
+	dir, base := filepath.Split(filePath)
+	splitted := strings.SplitN(base, ".", 2)
+	name := fmt.Sprintf("%s-%s", splitted[0], t.UTC().Format(partition.layout()))
+	if len(splitted) == 2 {
+		name += "." + splitted[1]
+	}
+	return dir + name
+}
+
+// ParseTimePartitionFilename returns the time interval covered by a file
+// created with MakeTimePartitionFilename
+// ok is false if the file is not partitioned by time
+func ParseTimePartitionFilename(filePath string) (from, to time.Time, ok bool) {
+	base := strings.SplitN(path.Base(filepath.ToSlash(filePath)), ".", 2)[0]
+	for _, partition := range []TimePartition{DailyPartition, MonthlyPartition} {
+		layout := partition.layout()
+		if len(base) <= len(layout) || base[len(base)-len(layout)-1] != '-' {
+			continue
+		}
+		parsed, err := time.Parse(layout, base[len(base)-len(layout):])
+		if err == nil {
+			return parsed, partition.Next(parsed), true
+		}
+	}
+	return time.Time{}, time.Time{}, false
+}
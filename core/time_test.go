@@ -0,0 +1,73 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTime(t *testing.T) {
+	parsed, err := ParseTime("2020-03-01")
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC), parsed)
+	parsed, err = ParseTime("2020-03-01T12:30:00Z")
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2020, 3, 1, 12, 30, 0, 0, time.UTC), parsed)
+	_, err = ParseTime("03/01/2020")
+	assert.NotNil(t, err)
+}
+
+func TestTimeRange(t *testing.T) {
+	timeRange := NewTimeRange(
+		time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2020, 4, 1, 0, 0, 0, 0, time.UTC))
+	assert.True(t, timeRange.Contains(time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)))
+	assert.False(t, timeRange.Contains(time.Date(2020, 4, 1, 0, 0, 0, 0, time.UTC)))
+	assert.True(t, TimeRange{}.Contains(time.Date(2020, 4, 1, 0, 0, 0, 0, time.UTC)))
+	assert.True(t, timeRange.Overlaps(
+		time.Date(2020, 2, 28, 0, 0, 0, 0, time.UTC), time.Date(2020, 3, 2, 0, 0, 0, 0, time.UTC)))
+	assert.False(t, timeRange.Overlaps(
+		time.Date(2020, 2, 1, 0, 0, 0, 0, time.UTC), time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestTimePartitionFilename(t *testing.T) {
+	blockTime := time.Date(2020, 3, 27, 20, 52, 50, 0, time.UTC)
+	assert.Equal(t, "data/eos-2020-03.jsonl.gz",
+		MakeTimePartitionFilename("data/eos.jsonl.gz", blockTime, MonthlyPartition))
+	assert.Equal(t, "eos-2020-03-27.jsonl.gz",
+		MakeTimePartitionFilename("eos.jsonl.gz", blockTime, DailyPartition))
+
+	from, to, ok := ParseTimePartitionFilename("data/eos-2020-03.jsonl.gz")
+	assert.True(t, ok)
+	assert.Equal(t, time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC), from)
+	assert.Equal(t, time.Date(2020, 4, 1, 0, 0, 0, 0, time.UTC), to)
+
+	from, to, ok = ParseTimePartitionFilename("eos-2020-03-27.jsonl.gz")
+	assert.True(t, ok)
+	assert.Equal(t, time.Date(2020, 3, 27, 0, 0, 0, 0, time.UTC), from)
+	assert.Equal(t, time.Date(2020, 3, 28, 0, 0, 0, 0, time.UTC), to)
+
+	_, _, ok = ParseTimePartitionFilename(EOSValidBlocksFilename)
+	assert.False(t, ok)
+}
+
+func TestTimePartitionFilenameRoundTrip(t *testing.T) {
+	blockTime := time.Date(2020, 3, 27, 20, 52, 50, 0, time.UTC)
+	cases := []struct {
+		filePath string
+		expected string
+	}{
+		{"./dir/x.jsonl.gz", "./dir/x-2020-03-27.jsonl.gz"},
+		{"/home/user/.cache/out/eos.jsonl.gz", "/home/user/.cache/out/eos-2020-03-27.jsonl.gz"},
+		{"data.d/blocks", "data.d/blocks-2020-03-27"},
+	}
+	for _, c := range cases {
+		filename := MakeTimePartitionFilename(c.filePath, blockTime, DailyPartition)
+		assert.Equal(t, c.expected, filename)
+		from, to, ok := ParseTimePartitionFilename(filename)
+		assert.True(t, ok, filename)
+		assert.Equal(t, time.Date(2020, 3, 27, 0, 0, 0, 0, time.UTC), from)
+		assert.Equal(t, time.Date(2020, 3, 28, 0, 0, 0, 0, time.UTC), to)
+	}
+}
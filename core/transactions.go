@@ -0,0 +1,108 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// TransactionPattern counts the transactions with the same sequence of actions
+type TransactionPattern struct {
+	Pattern string
+	Count   uint64
+}
+
+// GetTransactionPattern returns the names of the actions of the transaction,
+// where consecutive repetitions are collapsed, e.g. transfer*3,bet
+func GetTransactionPattern(transaction Transaction) string {
+	var parts []string
+	for i := 0; i < len(transaction.Actions); {
+		name := transaction.Actions[i].Name()
+		j := i + 1
+		for j < len(transaction.Actions) && transaction.Actions[j].Name() == name {
+			j++
+		}
+		if j-i > 1 {
+			name = fmt.Sprintf("%s*%d", name, j-i)
+		}
+		parts = append(parts, name)
+		i = j
+	}
+	return strings.Join(parts, ",")
+}
+
+// ActionsPerTransaction computes the distribution of the number of actions
+// per transaction and counts the patterns of multi-action transactions
+// Transactions without actions, such as EOS deferred transactions, are ignored
+type ActionsPerTransaction struct {
+	Distribution     *ValueDistribution
+	MultiActionCount uint64
+	patterns         map[string]uint64
+	patternsLimit    int
+}
+
+func NewActionsPerTransaction() *ActionsPerTransaction {
+	return &ActionsPerTransaction{
+		Distribution:  NewValueDistribution(),
+		patterns:      make(map[string]uint64),
+		patternsLimit: DefaultNestedResults,
+	}
+}
+
+// SetPatternsLimit sets the number of patterns output, 0 for unlimited
+func (a *ActionsPerTransaction) SetPatternsLimit(limit int) *ActionsPerTransaction {
+	a.patternsLimit = limit
+	return a
+}
+
+func (a *ActionsPerTransaction) AddBlock(block Block) {
+	for _, transaction := range block.ListTransactions() {
+		if len(transaction.Actions) == 0 {
+			continue
+		}
+		a.Distribution.Add(uint64(len(transaction.Actions)))
+		if len(transaction.Actions) > 1 {
+			a.MultiActionCount++
+			a.patterns[GetTransactionPattern(transaction)]++
+		}
+	}
+}
+
+// Patterns returns the patterns of multi-action transactions by decreasing count
+func (a *ActionsPerTransaction) Patterns() []TransactionPattern {
+	patterns := make([]TransactionPattern, 0, len(a.patterns))
+	for pattern, count := range a.patterns {
+		patterns = append(patterns, TransactionPattern{Pattern: pattern, Count: count})
+	}
+	sort.Slice(patterns, func(i, j int) bool {
+		if patterns[i].Count == patterns[j].Count {
+			return patterns[i].Pattern < patterns[j].Pattern
+		}
+		return patterns[i].Count > patterns[j].Count
+	})
+	if a.patternsLimit > 0 && len(patterns) > a.patternsLimit {
+		patterns = patterns[:a.patternsLimit]
+	}
+	return patterns
+}
+
+func (a *ActionsPerTransaction) MarshalJSON() ([]byte, error) {
+	summary := a.Distribution.Summary()
+	var multiActionRatio float64
+	if summary.Count > 0 {
+		multiActionRatio = float64(a.MultiActionCount) / float64(summary.Count)
+	}
+	return json.Marshal(map[string]interface{}{
+		"TransactionsCount": summary.Count,
+		"MultiActionCount":  a.MultiActionCount,
+		"MultiActionRatio":  multiActionRatio,
+		"Distribution":      summary,
+		"PatternsCount":     len(a.patterns),
+		"Patterns":          a.Patterns(),
+	})
+}
+
+func (a *ActionsPerTransaction) Result() interface{} {
+	return a
+}
@@ -0,0 +1,59 @@
+package core
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testTransactions groups the default test actions in a transaction with a single
+// action, a transaction with three actions and an empty transaction
+func testTransactions(actions []Action) []Transaction {
+	return []Transaction{
+		{ID: "a", Index: 0, Signer: "alice", Actions: actions[:1]},
+		{ID: "b", Index: 1, Signer: "bob", Actions: []Action{actions[1], actions[1], actions[2]}},
+		{ID: "c", Index: 2},
+	}
+}
+
+func TestGetTransactionPattern(t *testing.T) {
+	block := newTestBlock()
+	block.transactions = testTransactions(block.actions)
+	assert.Equal(t, "transfer", GetTransactionPattern(block.transactions[0]))
+	assert.Equal(t, "transfer*2,bet", GetTransactionPattern(block.transactions[1]))
+	assert.Equal(t, "", GetTransactionPattern(block.transactions[2]))
+}
+
+func TestActionsPerTransaction(t *testing.T) {
+	actionsPerTransaction := NewActionsPerTransaction()
+	block := newTestBlock()
+	block.transactions = testTransactions(block.actions)
+	actionsPerTransaction.AddBlock(block)
+	actionsPerTransaction.AddBlock(newTestBlock())
+
+	summary := actionsPerTransaction.Distribution.Summary()
+	assert.Equal(t, uint64(5), summary.Count)
+	assert.Equal(t, uint64(7), summary.Total)
+	assert.Equal(t, uint64(3), summary.Max)
+	assert.Equal(t, uint64(1), actionsPerTransaction.MultiActionCount)
+	assert.Equal(t, []TransactionPattern{{"transfer*2,bet", 1}},
+		actionsPerTransaction.Patterns())
+
+	_, err := json.Marshal(actionsPerTransaction)
+	assert.Nil(t, err)
+}
+
+func TestFilteredListTransactions(t *testing.T) {
+	filter, err := ParseFilter(`name == "bet"`)
+	assert.Nil(t, err)
+	block := newTestBlock()
+	block.transactions = testTransactions(block.actions)
+	filtered := filter.Apply(block)
+	transactions := filtered.ListTransactions()
+	if assert.Len(t, transactions, 1) {
+		assert.Equal(t, "b", transactions[0].ID)
+		assert.Len(t, transactions[0].Actions, 1)
+	}
+	assert.Equal(t, 1, filtered.TransactionsCount())
+}
@@ -0,0 +1,236 @@
+package core
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+type TransferFormat int
+
+const (
+	CSVTransferFormat TransferFormat = iota
+	JSONLTransferFormat
+	ParquetTransferFormat
+)
+
+func GetTransferFormat(name string) (TransferFormat, error) {
+	switch name {
+	case "csv":
+		return CSVTransferFormat, nil
+	case "jsonl":
+		return JSONLTransferFormat, nil
+	case "parquet":
+		return ParquetTransferFormat, nil
+	default:
+		return CSVTransferFormat, fmt.Errorf("no transfer format %s", name)
+	}
+}
+
+// InferTransferFormat returns the format of a transfers file from its extension,
+// e.g. transfers.jsonl or transfers.csv.gz
+func InferTransferFormat(filePath string) (TransferFormat, error) {
+	extension := path.Ext(strings.TrimSuffix(filePath, ".gz"))
+	return GetTransferFormat(strings.TrimPrefix(extension, "."))
+}
+
+// TransferColumns are the columns of exported transfers, shared by all blockchains
+var TransferColumns = []string{
+	"block", "time", "transaction", "from", "to", "amount", "asset", "issuer", "memo",
+}
+
+// TransferRecord is a transfer with the block and transaction containing it
+type TransferRecord struct {
+	Block       uint64
+	Time        time.Time
+	Transaction string
+	Transfer
+	Memo string
+}
+
+// Row returns the values of the record in the order of TransferColumns
+func (r TransferRecord) Row() []string {
+	return []string{
+		strconv.FormatUint(r.Block, 10),
+		r.Time.UTC().Format(time.RFC3339Nano),
+		r.Transaction,
+		r.From,
+		r.To,
+		FormatDecimal(r.Amount.Value),
+		r.Amount.Asset,
+		r.Amount.Issuer,
+		r.Memo,
+	}
+}
+
+func (r TransferRecord) MarshalJSON() ([]byte, error) {
+	row := r.Row()
+	fields := make(map[string]interface{}, len(row))
+	for i, column := range TransferColumns {
+		fields[column] = row[i]
+	}
+	fields["block"] = r.Block
+	return json.Marshal(fields)
+}
+
+// TransferExtractor is implemented by blockchains with transfers which
+// are not returned by TransferAction, such as token contract calls
+type TransferExtractor interface {
+	ExtractTransfers(block Block) []TransferRecord
+}
+
+// ExtractTransfers returns the transfers of the block using the extractor
+// of the blockchain if it has one, and ActionTransfers otherwise
+func ExtractTransfers(blockchain Blockchain, block Block) []TransferRecord {
+	if extractor, ok := blockchain.(TransferExtractor); ok {
+		return extractor.ExtractTransfers(block)
+	}
+	return ActionTransfers(block)
+}
+
+// ActionTransfers returns the transfers of the actions implementing TransferAction,
+// with their memo if the action has a memo data field
+func ActionTransfers(block Block) []TransferRecord {
+	var records []TransferRecord
+	for _, transaction := range block.ListTransactions() {
+		for _, action := range transaction.Actions {
+			transfer, ok := GetTransfer(action)
+			if !ok {
+				continue
+			}
+			records = append(records, TransferRecord{
+				Block:       block.Number(),
+				Time:        block.Time(),
+				Transaction: transaction.ID,
+				Transfer:    transfer,
+				Memo:        GetDataField(action, "memo"),
+			})
+		}
+	}
+	return records
+}
+
+// parquetTransfer is the Parquet row of a transfer record, with the columns
+// of TransferColumns, amounts being kept as strings to remain exact
+type parquetTransfer struct {
+	Block       int64  `parquet:"name=block, type=INT64"`
+	Time        int64  `parquet:"name=time, type=TIMESTAMP_MICROS"`
+	Transaction string `parquet:"name=transaction, type=UTF8, encoding=PLAIN_DICTIONARY"`
+	From        string `parquet:"name=from, type=UTF8, encoding=PLAIN_DICTIONARY"`
+	To          string `parquet:"name=to, type=UTF8, encoding=PLAIN_DICTIONARY"`
+	Amount      string `parquet:"name=amount, type=UTF8"`
+	Asset       string `parquet:"name=asset, type=UTF8, encoding=PLAIN_DICTIONARY"`
+	Issuer      string `parquet:"name=issuer, type=UTF8, encoding=PLAIN_DICTIONARY"`
+	Memo        string `parquet:"name=memo, type=UTF8"`
+}
+
+func (r TransferRecord) parquetRow() parquetTransfer {
+	row := r.Row()
+	return parquetTransfer{
+		Block:       int64(r.Block),
+		Time:        r.Time.UnixNano() / int64(time.Microsecond),
+		Transaction: row[2],
+		From:        row[3],
+		To:          row[4],
+		Amount:      row[5],
+		Asset:       row[6],
+		Issuer:      row[7],
+		Memo:        row[8],
+	}
+}
+
+// TransferWriter writes transfer records in CSV, JSONL or Parquet
+type TransferWriter struct {
+	csvWriter     *csv.Writer
+	encoder       *json.Encoder
+	parquetWriter *writer.ParquetWriter
+}
+
+func NewTransferWriter(output io.Writer, format TransferFormat) (*TransferWriter, error) {
+	switch format {
+	case CSVTransferFormat:
+		csvWriter := csv.NewWriter(output)
+		if err := csvWriter.Write(TransferColumns); err != nil {
+			return nil, err
+		}
+		return &TransferWriter{csvWriter: csvWriter}, nil
+	case JSONLTransferFormat:
+		return &TransferWriter{encoder: json.NewEncoder(output)}, nil
+	case ParquetTransferFormat:
+		parquetWriter, err := writer.NewParquetWriter(&parquetOutput{output}, new(parquetTransfer), 1)
+		if err != nil {
+			return nil, err
+		}
+		return &TransferWriter{parquetWriter: parquetWriter}, nil
+	default:
+		return nil, fmt.Errorf("no such transfer format %d", format)
+	}
+}
+
+func (w *TransferWriter) Write(record TransferRecord) error {
+	switch {
+	case w.csvWriter != nil:
+		return w.csvWriter.Write(record.Row())
+	case w.parquetWriter != nil:
+		return w.parquetWriter.Write(record.parquetRow())
+	default:
+		return w.encoder.Encode(record)
+	}
+}
+
+// Flush flushes the buffered records, and writes the footer of Parquet
+// files, so it must only be called once all the records are written
+func (w *TransferWriter) Flush() error {
+	switch {
+	case w.csvWriter != nil:
+		w.csvWriter.Flush()
+		return w.csvWriter.Error()
+	case w.parquetWriter != nil:
+		return w.parquetWriter.WriteStop()
+	default:
+		return nil
+	}
+}
+
+// TransferExporter writes the transfers of the blocks as they are added
+type TransferExporter struct {
+	TransfersCount uint64
+	blockchain     Blockchain
+	writer         *TransferWriter
+	err            error
+}
+
+func NewTransferExporter(blockchain Blockchain, writer *TransferWriter) *TransferExporter {
+	return &TransferExporter{blockchain: blockchain, writer: writer}
+}
+
+func (e *TransferExporter) AddBlock(block Block) {
+	if e.err != nil {
+		return
+	}
+	for _, record := range ExtractTransfers(e.blockchain, block) {
+		if e.err = e.writer.Write(record); e.err != nil {
+			return
+		}
+		e.TransfersCount++
+	}
+}
+
+// Finalize flushes the writer and returns the first write error, if any
+func (e *TransferExporter) Finalize() error {
+	if e.err != nil {
+		return e.err
+	}
+	return e.writer.Flush()
+}
+
+func (e *TransferExporter) Result() interface{} {
+	return e
+}
@@ -0,0 +1,107 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xitongsys/parquet-go/reader"
+	"github.com/xitongsys/parquet-go/source"
+)
+
+func TestInferTransferFormat(t *testing.T) {
+	format, err := InferTransferFormat("transfers.csv.gz")
+	assert.Nil(t, err)
+	assert.Equal(t, CSVTransferFormat, format)
+	format, err = InferTransferFormat("transfers.jsonl")
+	assert.Nil(t, err)
+	assert.Equal(t, JSONLTransferFormat, format)
+	format, err = InferTransferFormat("transfers.parquet")
+	assert.Nil(t, err)
+	assert.Equal(t, ParquetTransferFormat, format)
+	_, err = InferTransferFormat("transfers.txt")
+	assert.NotNil(t, err)
+}
+
+type parquetInput struct {
+	*bytes.Reader
+}
+
+func (i *parquetInput) Write(p []byte) (int, error) {
+	return 0, errors.New("read-only")
+}
+
+func (i *parquetInput) Close() error {
+	return nil
+}
+
+func (i *parquetInput) Open(name string) (source.ParquetFile, error) {
+	return &parquetInput{bytes.NewReader(i.copy())}, nil
+}
+
+func (i *parquetInput) Create(name string) (source.ParquetFile, error) {
+	return nil, errors.New("read-only")
+}
+
+func (i *parquetInput) copy() []byte {
+	content := make([]byte, i.Size())
+	i.ReadAt(content, 0)
+	return content
+}
+
+func TestExportTransfers(t *testing.T) {
+	block := newTestBlock(
+		newTestTransfer("alice", "bob", "2.5", "EOS"),
+		testAction{"bet", "alice", "betdicegroup"},
+		newTestTransfer("bob", "carol", "1", "BET"))
+	records := ActionTransfers(block)
+	assert.Len(t, records, 2)
+	assert.Equal(t, []string{"100", "2020-03-01T12:00:00Z", "", "alice", "bob", "2.5", "EOS", "eosio.token", ""},
+		records[0].Row())
+
+	var output bytes.Buffer
+	writer, err := NewTransferWriter(&output, CSVTransferFormat)
+	assert.Nil(t, err)
+	exporter := NewTransferExporter(nil, writer)
+	exporter.AddBlock(block)
+	assert.Nil(t, exporter.Finalize())
+	assert.Equal(t, uint64(2), exporter.TransfersCount)
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	assert.Equal(t, []string{
+		"block,time,transaction,from,to,amount,asset,issuer,memo",
+		"100,2020-03-01T12:00:00Z,,alice,bob,2.5,EOS,eosio.token,",
+		"100,2020-03-01T12:00:00Z,,bob,carol,1,BET,eosio.token,",
+	}, lines)
+
+	output.Reset()
+	writer, err = NewTransferWriter(&output, JSONLTransferFormat)
+	assert.Nil(t, err)
+	assert.Nil(t, writer.Write(records[1]))
+	var decoded map[string]interface{}
+	assert.Nil(t, json.Unmarshal(output.Bytes(), &decoded))
+	assert.Equal(t, float64(100), decoded["block"])
+	assert.Equal(t, "carol", decoded["to"])
+	assert.Equal(t, "BET", decoded["asset"])
+
+	output.Reset()
+	writer, err = NewTransferWriter(&output, ParquetTransferFormat)
+	assert.Nil(t, err)
+	exporter = NewTransferExporter(nil, writer)
+	exporter.AddBlock(block)
+	assert.Nil(t, exporter.Finalize())
+	parquetReader, err := reader.NewParquetReader(
+		&parquetInput{bytes.NewReader(output.Bytes())}, new(parquetTransfer), 1)
+	assert.Nil(t, err)
+	defer parquetReader.ReadStop()
+	assert.Equal(t, int64(2), parquetReader.GetNumRows())
+	rows := make([]parquetTransfer, 2)
+	assert.Nil(t, parquetReader.Read(&rows))
+	assert.Equal(t, records[0].parquetRow(), rows[0])
+	assert.Equal(t, parquetTransfer{
+		Block: 100, Time: 1583064000000000, From: "bob", To: "carol",
+		Amount: "1", Asset: "BET", Issuer: "eosio.token",
+	}, rows[1])
+}
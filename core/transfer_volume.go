@@ -0,0 +1,179 @@
+package core
+
+import (
+	"encoding/json"
+	"math/big"
+	"sort"
+	"time"
+)
+
+// Volume is the number and exact total amount of transfers
+type Volume struct {
+	Count  uint64
+	Amount *big.Rat
+}
+
+func newVolume() *Volume {
+	return &Volume{Amount: new(big.Rat)}
+}
+
+func (v *Volume) add(count uint64, amount *big.Rat) {
+	v.Count += count
+	v.Amount.Add(v.Amount, amount)
+}
+
+func (v *Volume) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"Count":  v.Count,
+		"Amount": FormatDecimal(v.Amount),
+	})
+}
+
+// AccountVolume is the volume sent and received by an account for an asset
+type AccountVolume struct {
+	Account  string
+	Sent     *Volume
+	Received *Volume
+}
+
+func newAccountVolume(account string) *AccountVolume {
+	return &AccountVolume{Account: account, Sent: newVolume(), Received: newVolume()}
+}
+
+func (a *AccountVolume) total() *big.Rat {
+	return new(big.Rat).Add(a.Sent.Amount, a.Received.Amount)
+}
+
+type assetVolume struct {
+	total    *Volume
+	accounts map[string]*AccountVolume
+}
+
+func newAssetVolume() *assetVolume {
+	return &assetVolume{total: newVolume(), accounts: make(map[string]*AccountVolume)}
+}
+
+func (a *assetVolume) account(name string) *AccountVolume {
+	if _, ok := a.accounts[name]; !ok {
+		a.accounts[name] = newAccountVolume(name)
+	}
+	return a.accounts[name]
+}
+
+// topAccounts returns the accounts sorted by decreasing volume
+// sent and received, limited to limit accounts if limit is positive
+func (a *assetVolume) topAccounts(limit int) []*AccountVolume {
+	accounts := make([]*AccountVolume, 0, len(a.accounts))
+	for _, account := range a.accounts {
+		accounts = append(accounts, account)
+	}
+	sort.Slice(accounts, func(i, j int) bool {
+		comparison := accounts[i].total().Cmp(accounts[j].total())
+		if comparison == 0 {
+			return accounts[i].Account < accounts[j].Account
+		}
+		return comparison > 0
+	})
+	if limit > 0 && len(accounts) > limit {
+		accounts = accounts[:limit]
+	}
+	return accounts
+}
+
+// TransferVolume sums the amounts transferred for each asset, account
+// and period using exact decimal arithmetic
+type TransferVolume struct {
+	Duration     Duration
+	assets       map[string]*assetVolume
+	overTime     map[time.Time]map[string]*Volume
+	accountLimit int
+}
+
+func NewTransferVolume(duration Duration) *TransferVolume {
+	return &TransferVolume{
+		Duration:     duration,
+		assets:       make(map[string]*assetVolume),
+		overTime:     make(map[time.Time]map[string]*Volume),
+		accountLimit: DefaultNestedResults,
+	}
+}
+
+// SetAccountsLimit sets the number of accounts output for each asset, 0 for unlimited
+func (v *TransferVolume) SetAccountsLimit(limit int) *TransferVolume {
+	v.accountLimit = limit
+	return v
+}
+
+func (v *TransferVolume) AddBlock(block Block) {
+	for _, action := range block.ListActions() {
+		transfer, ok := GetTransfer(action)
+		if !ok {
+			continue
+		}
+		name := transfer.Amount.AssetName()
+		amount := transfer.Amount.Value
+		if _, ok := v.assets[name]; !ok {
+			v.assets[name] = newAssetVolume()
+		}
+		asset := v.assets[name]
+		asset.total.add(1, amount)
+		asset.account(transfer.From).Sent.add(1, amount)
+		asset.account(transfer.To).Received.add(1, amount)
+
+		if v.Duration.IsZero() {
+			continue
+		}
+		group := v.Duration.Truncate(block.Time())
+		if _, ok := v.overTime[group]; !ok {
+			v.overTime[group] = make(map[string]*Volume)
+		}
+		if _, ok := v.overTime[group][name]; !ok {
+			v.overTime[group][name] = newVolume()
+		}
+		v.overTime[group][name].add(1, amount)
+	}
+}
+
+// Total returns the volume of the asset, e.g. EOS@eosio.token
+func (v *TransferVolume) Total(asset string) *Volume {
+	if volume, ok := v.assets[asset]; ok {
+		return volume.total
+	}
+	return newVolume()
+}
+
+// Account returns the volume sent and received by the account for the asset
+func (v *TransferVolume) Account(asset, account string) *AccountVolume {
+	if volume, ok := v.assets[asset]; ok {
+		if accountVolume, ok := volume.accounts[account]; ok {
+			return accountVolume
+		}
+	}
+	return newAccountVolume(account)
+}
+
+func (v *TransferVolume) OverTime() map[time.Time]map[string]*Volume {
+	return v.overTime
+}
+
+func (v *TransferVolume) MarshalJSON() ([]byte, error) {
+	assets := make(map[string]interface{})
+	for name, asset := range v.assets {
+		assets[name] = map[string]interface{}{
+			"Count":         asset.total.Count,
+			"Amount":        FormatDecimal(asset.total.Amount),
+			"AccountsCount": len(asset.accounts),
+			"Accounts":      asset.topAccounts(v.accountLimit),
+		}
+	}
+	result := map[string]interface{}{"Assets": assets}
+	if !v.Duration.IsZero() {
+		result["Duration"] = v.Duration
+		result["OverTime"] = v.overTime
+	}
+	return json.Marshal(result)
+}
+
+func (v *TransferVolume) Result() interface{} {
+	return v
+}
@@ -0,0 +1,109 @@
+package core
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransferVolume(t *testing.T) {
+	volume := NewTransferVolume(NewDuration(time.Hour)).SetAccountsLimit(1)
+	block := newTestBlock()
+	block.actions = append(block.actions,
+		newTestTransfer("alice", "bob", "0.1000", "EOS"),
+		newTestTransfer("alice", "carol", "0.2000", "EOS"),
+		newTestTransfer("bob", "alice", "5", "USD"),
+	)
+	volume.AddBlock(block)
+
+	total := volume.Total("EOS@eosio.token")
+	assert.Equal(t, uint64(2), total.Count)
+	assert.Equal(t, "0.3", FormatDecimal(total.Amount))
+	alice := volume.Account("EOS@eosio.token", "alice")
+	assert.Equal(t, "0.3", FormatDecimal(alice.Sent.Amount))
+	assert.Equal(t, uint64(0), alice.Received.Count)
+	assert.Equal(t, uint64(1), volume.Account("USD@eosio.token", "alice").Received.Count)
+
+	group := time.Date(2020, 3, 1, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, uint64(1), volume.OverTime()[group]["USD@eosio.token"].Count)
+
+	rawResult, err := json.Marshal(volume)
+	assert.Nil(t, err)
+	var result struct {
+		Assets map[string]struct {
+			Amount        string
+			AccountsCount int
+			Accounts      []struct{ Account string }
+		}
+	}
+	assert.Nil(t, json.Unmarshal(rawResult, &result))
+	assert.Equal(t, "0.3", result.Assets["EOS@eosio.token"].Amount)
+	assert.Equal(t, 3, result.Assets["EOS@eosio.token"].AccountsCount)
+	assert.Len(t, result.Assets["EOS@eosio.token"].Accounts, 1)
+	assert.Equal(t, "alice", result.Assets["EOS@eosio.token"].Accounts[0].Account)
+}
+
+func TestTransferVolumePerAccount(t *testing.T) {
+	start := testBlockTime
+	volume := NewTransferVolume(Duration{}).SetAccountsLimit(0)
+	volume.AddBlock(newTestBlock(
+		newTestTransfer("alice", "bob", "1.5", "EOS"),
+		newTestTransfer("bob", "carol", "0.5", "EOS"),
+		testAction{"bet", "alice", "betdicegroup"}).at(1, start))
+	volume.AddBlock(newTestBlock(
+		newTestTransfer("carol", "alice", "0.25", "EOS"),
+		newTestTransfer("alice", "bob", "2", "EOS")).at(2, start.Add(time.Minute)))
+
+	alice := volume.Account("EOS@eosio.token", "alice")
+	assert.Equal(t, uint64(2), alice.Sent.Count)
+	assert.Equal(t, "3.5", FormatDecimal(alice.Sent.Amount))
+	assert.Equal(t, uint64(1), alice.Received.Count)
+	assert.Equal(t, "0.25", FormatDecimal(alice.Received.Amount))
+	bob := volume.Account("EOS@eosio.token", "bob")
+	assert.Equal(t, "0.5", FormatDecimal(bob.Sent.Amount))
+	assert.Equal(t, "3.5", FormatDecimal(bob.Received.Amount))
+	assert.Equal(t, uint64(0), volume.Account("EOS@eosio.token", "dave").Sent.Count)
+	assert.Equal(t, uint64(0), volume.Account("BET@eosio.token", "alice").Sent.Count)
+
+	// accounts are sorted by volume sent and received
+	accounts := volume.assets["EOS@eosio.token"].topAccounts(0)
+	names := make([]string, len(accounts))
+	for i, account := range accounts {
+		names[i] = account.Account
+	}
+	assert.Equal(t, []string{"bob", "alice", "carol"}, names)
+
+	// no buckets without duration
+	assert.Empty(t, volume.OverTime())
+	rawResult, err := json.Marshal(volume)
+	assert.Nil(t, err)
+	assert.NotContains(t, string(rawResult), "OverTime")
+}
+
+func TestTransferVolumeOverTime(t *testing.T) {
+	start := testBlockTime
+	volume := NewTransferVolume(NewDuration(time.Hour))
+	volume.AddBlock(newTestBlock(
+		newTestTransfer("alice", "bob", "1.5", "EOS"),
+		newTestTransfer("alice", "bob", "10", "USD")).at(1, start.Add(5*time.Minute)))
+	volume.AddBlock(newTestBlock(
+		newTestTransfer("bob", "carol", "0.5", "EOS")).at(2, start.Add(59*time.Minute)))
+	volume.AddBlock(newTestBlock(
+		newTestTransfer("carol", "alice", "0.25", "EOS")).at(3, start.Add(time.Hour)))
+
+	overTime := volume.OverTime()
+	assert.Len(t, overTime, 2)
+	first := overTime[start]
+	assert.Equal(t, uint64(2), first["EOS@eosio.token"].Count)
+	assert.Equal(t, "2", FormatDecimal(first["EOS@eosio.token"].Amount))
+	assert.Equal(t, "10", FormatDecimal(first["USD@eosio.token"].Amount))
+	second := overTime[start.Add(time.Hour)]
+	assert.Equal(t, uint64(1), second["EOS@eosio.token"].Count)
+	assert.Equal(t, "0.25", FormatDecimal(second["EOS@eosio.token"].Amount))
+	assert.Nil(t, second["USD@eosio.token"])
+
+	assert.Equal(t, uint64(3), volume.Total("EOS@eosio.token").Count)
+	assert.Equal(t, "2.25", FormatDecimal(volume.Total("EOS@eosio.token").Amount))
+}
@@ -0,0 +1,399 @@
+package eos
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const nameCharacters = ".12345abcdefghijklmnopqrstuvwxyz"
+
+var blockTimestampEpoch = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// fixedSizes are the sizes of the built-in types output as hex
+var fixedSizes = map[string]int{
+	"float128":    16,
+	"checksum160": 20,
+	"checksum256": 32,
+	"checksum512": 64,
+}
+
+type ABIType struct {
+	NewTypeName string `json:"new_type_name"`
+	Type        string
+}
+
+type ABIField struct {
+	Name string
+	Type string
+}
+
+type ABIStruct struct {
+	Name   string
+	Base   string
+	Fields []ABIField
+}
+
+type ABIAction struct {
+	Name string
+	Type string
+}
+
+type ABIVariant struct {
+	Name  string
+	Types []string
+}
+
+// ABI describes the binary format of the actions of a contract
+type ABI struct {
+	Version  string
+	Types    []ABIType
+	Structs  []ABIStruct
+	Actions  []ABIAction
+	Variants []ABIVariant
+	types    map[string]string
+	structs  map[string]*ABIStruct
+	actions  map[string]string
+	variants map[string]*ABIVariant
+}
+
+// ParseABI parses a JSON ABI, either given directly
+// or as returned by the get_abi endpoint of a node
+func ParseABI(rawABI []byte) (*ABI, error) {
+	var response struct {
+		ABI *ABI
+	}
+	if err := fastJson.Unmarshal(rawABI, &response); err != nil {
+		return nil, err
+	}
+	abi := response.ABI
+	if abi == nil {
+		abi = &ABI{}
+		if err := fastJson.Unmarshal(rawABI, abi); err != nil {
+			return nil, err
+		}
+	}
+	abi.types = make(map[string]string)
+	for _, abiType := range abi.Types {
+		abi.types[abiType.NewTypeName] = abiType.Type
+	}
+	abi.structs = make(map[string]*ABIStruct)
+	for i := range abi.Structs {
+		abi.structs[abi.Structs[i].Name] = &abi.Structs[i]
+	}
+	abi.actions = make(map[string]string)
+	for _, action := range abi.Actions {
+		abi.actions[action.Name] = action.Type
+	}
+	abi.variants = make(map[string]*ABIVariant)
+	for i := range abi.Variants {
+		abi.variants[abi.Variants[i].Name] = &abi.Variants[i]
+	}
+	return abi, nil
+}
+
+// DecodeAction decodes the binary data of the given action
+func (a *ABI) DecodeAction(name string, data []byte) (map[string]interface{}, error) {
+	actionType, ok := a.actions[name]
+	if !ok {
+		return nil, fmt.Errorf("no action %s in ABI", name)
+	}
+	decoder := &abiDecoder{abi: a, reader: bytes.NewReader(data)}
+	value, err := decoder.decode(actionType)
+	if err != nil {
+		return nil, err
+	}
+	fields, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("action %s type %s is not a struct", name, actionType)
+	}
+	return fields, nil
+}
+
+type abiDecoder struct {
+	abi    *ABI
+	reader *bytes.Reader
+}
+
+func (d *abiDecoder) resolve(typeName string) string {
+	for i := 0; i < 32; i++ {
+		aliased, ok := d.abi.types[typeName]
+		if !ok {
+			return typeName
+		}
+		typeName = aliased
+	}
+	return typeName
+}
+
+func (d *abiDecoder) decode(typeName string) (interface{}, error) {
+	typeName = d.resolve(typeName)
+	switch {
+	case strings.HasSuffix(typeName, "$"):
+		if d.reader.Len() == 0 {
+			return nil, nil
+		}
+		return d.decode(strings.TrimSuffix(typeName, "$"))
+	case strings.HasSuffix(typeName, "?"):
+		present, err := d.reader.ReadByte()
+		if err != nil || present == 0 {
+			return nil, err
+		}
+		return d.decode(strings.TrimSuffix(typeName, "?"))
+	case strings.HasSuffix(typeName, "[]"):
+		count, err := d.readVarUint32()
+		if err != nil {
+			return nil, err
+		}
+		if int(count) > d.reader.Len() {
+			return nil, fmt.Errorf("invalid array length %d", count)
+		}
+		values := make([]interface{}, count)
+		for i := range values {
+			if values[i], err = d.decode(strings.TrimSuffix(typeName, "[]")); err != nil {
+				return nil, err
+			}
+		}
+		return values, nil
+	}
+	if abiStruct, ok := d.abi.structs[typeName]; ok {
+		return d.decodeStruct(abiStruct)
+	}
+	if variant, ok := d.abi.variants[typeName]; ok {
+		index, err := d.readVarUint32()
+		if err != nil {
+			return nil, err
+		}
+		if int(index) >= len(variant.Types) {
+			return nil, fmt.Errorf("invalid index %d for variant %s", index, typeName)
+		}
+		value, err := d.decode(variant.Types[index])
+		return []interface{}{variant.Types[index], value}, err
+	}
+	return d.decodeBuiltin(typeName)
+}
+
+func (d *abiDecoder) decodeStruct(abiStruct *ABIStruct) (map[string]interface{}, error) {
+	fields := make(map[string]interface{})
+	if abiStruct.Base != "" {
+		base, ok := d.abi.structs[d.resolve(abiStruct.Base)]
+		if !ok {
+			return nil, fmt.Errorf("unknown base %s of struct %s", abiStruct.Base, abiStruct.Name)
+		}
+		baseFields, err := d.decodeStruct(base)
+		if err != nil {
+			return nil, err
+		}
+		for name, value := range baseFields {
+			fields[name] = value
+		}
+	}
+	for _, field := range abiStruct.Fields {
+		if strings.HasSuffix(field.Type, "$") && d.reader.Len() == 0 {
+			continue
+		}
+		value, err := d.decode(field.Type)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %s", field.Name, err.Error())
+		}
+		fields[field.Name] = value
+	}
+	return fields, nil
+}
+
+func (d *abiDecoder) read(size int) ([]byte, error) {
+	if d.reader.Len() < size {
+		return nil, fmt.Errorf("expected %d bytes, got %d", size, d.reader.Len())
+	}
+	data := make([]byte, size)
+	if size == 0 {
+		return data, nil
+	}
+	_, err := d.reader.Read(data)
+	return data, err
+}
+
+func (d *abiDecoder) readUint(size int) (uint64, error) {
+	data, err := d.read(size)
+	if err != nil {
+		return 0, err
+	}
+	padded := make([]byte, 8)
+	copy(padded, data)
+	return binary.LittleEndian.Uint64(padded), nil
+}
+
+func (d *abiDecoder) readVarUint32() (uint32, error) {
+	var value uint32
+	for shift := uint(0); shift < 35; shift += 7 {
+		b, err := d.reader.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		value |= uint32(b&0x7f) << shift
+		if b&0x80 == 0 {
+			return value, nil
+		}
+	}
+	return 0, fmt.Errorf("varuint32 too long")
+}
+
+func (d *abiDecoder) readBytes() ([]byte, error) {
+	size, err := d.readVarUint32()
+	if err != nil {
+		return nil, err
+	}
+	return d.read(int(size))
+}
+
+// decodeName decodes an account or action name encoded in base 32
+func decodeName(value uint64) string {
+	name := make([]byte, 13)
+	for i := 0; i <= 12; i++ {
+		if i == 0 {
+			name[12-i] = nameCharacters[value&0x0f]
+			value >>= 4
+		} else {
+			name[12-i] = nameCharacters[value&0x1f]
+			value >>= 5
+		}
+	}
+	return strings.TrimRight(string(name), ".")
+}
+
+func decodeSymbolCode(value uint64) string {
+	var code strings.Builder
+	for ; value > 0; value >>= 8 {
+		code.WriteByte(byte(value & 0xff))
+	}
+	return code.String()
+}
+
+func formatAsset(amount int64, precision int, code string) string {
+	value := new(big.Rat).SetFrac(big.NewInt(amount),
+		new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(precision)), nil))
+	return value.FloatString(precision) + " " + code
+}
+
+func (d *abiDecoder) decodeInt128(signed bool) (string, error) {
+	data, err := d.read(16)
+	if err != nil {
+		return "", err
+	}
+	bigEndian := make([]byte, 16)
+	for i := range data {
+		bigEndian[15-i] = data[i]
+	}
+	value := new(big.Int).SetBytes(bigEndian)
+	if signed && data[15]&0x80 != 0 {
+		value.Sub(value, new(big.Int).Lsh(big.NewInt(1), 128))
+	}
+	return value.String(), nil
+}
+
+// decodeBuiltin decodes the built-in types of EOSIO ABIs
+// Keys and signatures are output as the hex of their key type and data
+func (d *abiDecoder) decodeBuiltin(typeName string) (interface{}, error) {
+	switch typeName {
+	case "bool":
+		value, err := d.reader.ReadByte()
+		return value != 0, err
+	case "int8":
+		value, err := d.readUint(1)
+		return int8(value), err
+	case "uint8":
+		value, err := d.readUint(1)
+		return uint8(value), err
+	case "int16":
+		value, err := d.readUint(2)
+		return int16(value), err
+	case "uint16":
+		value, err := d.readUint(2)
+		return uint16(value), err
+	case "int32":
+		value, err := d.readUint(4)
+		return int32(value), err
+	case "uint32":
+		value, err := d.readUint(4)
+		return uint32(value), err
+	case "int64":
+		value, err := d.readUint(8)
+		return int64(value), err
+	case "uint64":
+		return d.readUint(8)
+	case "int128", "uint128":
+		return d.decodeInt128(typeName == "int128")
+	case "varuint32":
+		return d.readVarUint32()
+	case "varint32":
+		value, err := d.readVarUint32()
+		return int32(value>>1) ^ -int32(value&1), err
+	case "float32":
+		value, err := d.readUint(4)
+		return math.Float32frombits(uint32(value)), err
+	case "float64":
+		value, err := d.readUint(8)
+		return math.Float64frombits(value), err
+	case "float128", "checksum160", "checksum256", "checksum512":
+		data, err := d.read(fixedSizes[typeName])
+		return hex.EncodeToString(data), err
+	case "public_key", "signature":
+		keyType, err := d.reader.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		size := 33
+		if typeName == "signature" {
+			size = 65
+		}
+		data, err := d.read(size)
+		return strconv.Itoa(int(keyType)) + ":" + hex.EncodeToString(data), err
+	case "name":
+		value, err := d.readUint(8)
+		return decodeName(value), err
+	case "string":
+		data, err := d.readBytes()
+		return string(data), err
+	case "bytes":
+		data, err := d.readBytes()
+		return hex.EncodeToString(data), err
+	case "time_point":
+		value, err := d.readUint(8)
+		return time.Unix(0, int64(value)*int64(time.Microsecond)).UTC().Format(timeLayout), err
+	case "time_point_sec":
+		value, err := d.readUint(4)
+		return time.Unix(int64(value), 0).UTC().Format(timeLayout), err
+	case "block_timestamp_type":
+		value, err := d.readUint(4)
+		slots := time.Duration(value) * slotDuration
+		return blockTimestampEpoch.Add(slots).Format(timeLayout), err
+	case "symbol_code":
+		value, err := d.readUint(8)
+		return decodeSymbolCode(value), err
+	case "symbol":
+		value, err := d.readUint(8)
+		return fmt.Sprintf("%d,%s", value&0xff, decodeSymbolCode(value>>8)), err
+	case "asset":
+		amount, err := d.readUint(8)
+		if err != nil {
+			return nil, err
+		}
+		symbol, err := d.readUint(8)
+		return formatAsset(int64(amount), int(symbol&0xff), decodeSymbolCode(symbol>>8)), err
+	case "extended_asset":
+		quantity, err := d.decodeBuiltin("asset")
+		if err != nil {
+			return nil, err
+		}
+		contract, err := d.decodeBuiltin("name")
+		return map[string]interface{}{"quantity": quantity, "contract": contract}, err
+	default:
+		return nil, fmt.Errorf("unknown type %s", typeName)
+	}
+}
@@ -0,0 +1,152 @@
+package eos
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type abiVersion struct {
+	block uint64
+	abi   *ABI
+}
+
+// abiEntry is loaded once, the other lookups of the account waiting for the load
+type abiEntry struct {
+	once     sync.Once
+	versions []abiVersion
+	err      error
+}
+
+// ABIStore loads the ABIs of contracts from a directory, where <account>.json
+// is used from the first block and <account>@<block>.json from the given block
+// If NodeURL is set, the current ABI of contracts without local ABIs is fetched
+// from the node and saved to the directory as <account>.json. As the node does
+// not return the block at which the ABI was set, fetched ABIs are used from the
+// first block, and blocks before the last update of the ABI may be decoded
+// incorrectly; older versions can be added as <account>@<block>.json
+// ABIs are cached per account and version, as are accounts without ABIs and
+// failed loads, so that the node is queried at most once per account
+type ABIStore struct {
+	Directory string
+	NodeURL   string
+	mutex     sync.Mutex
+	entries   map[string]*abiEntry
+}
+
+func NewABIStore(directory, nodeURL string) *ABIStore {
+	return &ABIStore{
+		Directory: directory,
+		NodeURL:   nodeURL,
+		entries:   make(map[string]*abiEntry),
+	}
+}
+
+// entry returns the entry of the account, only locking the store to look it up
+// so that loading the ABIs of an account does not block the other accounts
+func (s *ABIStore) entry(account string) *abiEntry {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	entry, ok := s.entries[account]
+	if !ok {
+		entry = &abiEntry{}
+		s.entries[account] = entry
+	}
+	return entry
+}
+
+// Get returns the ABI of the account used at the given block
+func (s *ABIStore) Get(account string, blockNumber uint64) (*ABI, error) {
+	entry := s.entry(account)
+	entry.once.Do(func() {
+		entry.versions, entry.err = s.load(account)
+	})
+	if entry.err != nil {
+		return nil, entry.err
+	}
+	versions := entry.versions
+	for i := len(versions) - 1; i >= 0; i-- {
+		if versions[i].block <= blockNumber {
+			return versions[i].abi, nil
+		}
+	}
+	return nil, fmt.Errorf("no ABI for %s at block %d", account, blockNumber)
+}
+
+func (s *ABIStore) load(account string) ([]abiVersion, error) {
+	var versions []abiVersion
+	if s.Directory != "" {
+		files, err := filepath.Glob(filepath.Join(s.Directory, account+"*.json"))
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			name := strings.TrimSuffix(filepath.Base(file), ".json")
+			var block uint64
+			if name != account {
+				rawBlock := strings.TrimPrefix(name, account+"@")
+				if block, err = strconv.ParseUint(rawBlock, 10, 64); err != nil || rawBlock == name {
+					continue
+				}
+			}
+			rawABI, err := ioutil.ReadFile(file)
+			if err != nil {
+				return nil, err
+			}
+			abi, err := ParseABI(rawABI)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %s", file, err.Error())
+			}
+			versions = append(versions, abiVersion{block: block, abi: abi})
+		}
+	}
+	if len(versions) == 0 && s.NodeURL != "" {
+		abi, err := s.fetch(account)
+		if err != nil {
+			return nil, err
+		}
+		if abi != nil {
+			versions = append(versions, abiVersion{abi: abi})
+		}
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i].block < versions[j].block })
+	return versions, nil
+}
+
+// fetch returns the current ABI of the account or nil if it has none
+func (s *ABIStore) fetch(account string) (*ABI, error) {
+	url := fmt.Sprintf("%s/v1/chain/get_abi", s.NodeURL)
+	data := fmt.Sprintf("{\"account_name\": %q}", account)
+	response, err := http.Post(url, "application/json", strings.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	rawABI, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("could not fetch ABI of %s: %s", account, response.Status)
+	}
+	abi, err := ParseABI(rawABI)
+	if err != nil || len(abi.Actions) == 0 {
+		return nil, err
+	}
+	if s.Directory != "" {
+		if err := os.MkdirAll(s.Directory, 0755); err != nil {
+			return nil, err
+		}
+		path := filepath.Join(s.Directory, account+".json")
+		if err := ioutil.WriteFile(path, rawABI, 0644); err != nil {
+			return nil, err
+		}
+	}
+	return abi, nil
+}
@@ -0,0 +1,225 @@
+package eos
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/danhper/blockchain-analyzer/core"
+	"github.com/danhper/blockchain-analyzer/processor"
+	"github.com/stretchr/testify/assert"
+)
+
+type rawAction struct {
+	Account string
+	Name    string
+	Data    json.RawMessage
+	HexData string `json:"hex_data"`
+}
+
+func readRawActions(t *testing.T) []rawAction {
+	var block struct {
+		Transactions []struct {
+			Trx json.RawMessage
+		}
+	}
+	assert.Nil(t, json.Unmarshal(core.ReadAllBlocks("eos")[0], &block))
+	var actions []rawAction
+	for _, transaction := range block.Transactions {
+		var trx struct {
+			Transaction struct {
+				Actions []rawAction
+			}
+		}
+		if json.Unmarshal(transaction.Trx, &trx) == nil {
+			actions = append(actions, trx.Transaction.Actions...)
+		}
+	}
+	return actions
+}
+
+func newFixtureABIStore() *ABIStore {
+	return NewABIStore(core.GetFixture(core.EOSABIsDirectory), "")
+}
+
+func TestDecodeName(t *testing.T) {
+	assert.Equal(t, "eosio.token", decodeName(0x5530ea033482a600))
+	assert.Equal(t, "", decodeName(0))
+}
+
+func TestABIDecodeAction(t *testing.T) {
+	store := newFixtureABIStore()
+	decodedCount := 0
+	for _, action := range readRawActions(t) {
+		key := action.Account + "::" + action.Name
+		if key != "eosio.token::transfer" && key != "delphioracle::write" {
+			continue
+		}
+		abi, err := store.Get(action.Account, 0)
+		assert.Nil(t, err)
+		data, err := hex.DecodeString(action.HexData)
+		assert.Nil(t, err)
+		decoded, err := abi.DecodeAction(action.Name, data)
+		assert.Nil(t, err)
+
+		var expected, actual interface{}
+		assert.Nil(t, json.Unmarshal(action.Data, &expected))
+		encoded, _ := json.Marshal(decoded)
+		assert.Nil(t, json.Unmarshal(encoded, &actual))
+		assert.Equal(t, expected, actual, key)
+		decodedCount++
+	}
+	assert.Equal(t, 171, decodedCount)
+
+	abi, _ := store.Get("eosio.token", 0)
+	_, err := abi.DecodeAction("transfer", []byte{1, 2})
+	assert.NotNil(t, err)
+	_, err = abi.DecodeAction("unknown", nil)
+	assert.NotNil(t, err)
+}
+
+func TestABIStoreVersions(t *testing.T) {
+	dir, err := ioutil.TempDir("", "abis")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	rawABI, err := ioutil.ReadFile(filepath.Join(core.GetFixture(core.EOSABIsDirectory), "eosio.token.json"))
+	assert.Nil(t, err)
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "eosio.token@100.json"), rawABI, 0644))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "eosio.token@200.json"), rawABI, 0644))
+
+	store := NewABIStore(dir, "")
+	_, err = store.Get("eosio.token", 50)
+	assert.NotNil(t, err)
+	first, err := store.Get("eosio.token", 150)
+	assert.Nil(t, err)
+	second, err := store.Get("eosio.token", 250)
+	assert.Nil(t, err)
+	assert.True(t, first != second)
+	cached, _ := store.Get("eosio.token", 199)
+	assert.True(t, first == cached)
+	_, err = store.Get("eosio", 250)
+	assert.NotNil(t, err)
+}
+
+func TestABIStoreFetch(t *testing.T) {
+	rawABI, err := ioutil.ReadFile(filepath.Join(core.GetFixture(core.EOSABIsDirectory), "delphioracle.json"))
+	assert.Nil(t, err)
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		assert.Equal(t, "/v1/chain/get_abi", r.URL.Path)
+		fmt.Fprint(w, string(rawABI))
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "abis")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	store := NewABIStore(dir, server.URL)
+	abi, err := store.Get("delphioracle", 120893628)
+	assert.Nil(t, err)
+	assert.Len(t, abi.Actions, 1)
+	_, err = store.Get("delphioracle", 120893629)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, requests)
+	_, err = os.Stat(filepath.Join(dir, "delphioracle.json"))
+	assert.Nil(t, err)
+}
+
+func TestABIStoreFetchError(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	store := NewABIStore("", server.URL)
+	_, err := store.Get("delphioracle", 120893628)
+	assert.NotNil(t, err)
+	_, err = store.Get("delphioracle", 120893629)
+	assert.NotNil(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+}
+
+func TestABIStoreConcurrentFetch(t *testing.T) {
+	release := make(chan bool)
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		<-release
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	store := NewABIStore(core.GetFixture(core.EOSABIsDirectory), server.URL)
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := store.Get("unknown", 1)
+			assert.NotNil(t, err)
+		}()
+	}
+	// local ABIs do not wait for the fetch of other accounts
+	for atomic.LoadInt32(&requests) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	abi, err := store.Get("eosio.token", 1)
+	assert.Nil(t, err)
+	assert.NotNil(t, abi)
+	close(release)
+	wg.Wait()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+}
+
+func TestActionDataField(t *testing.T) {
+	for _, raw := range readRawActions(t) {
+		if raw.Account != "delphioracle" {
+			continue
+		}
+		jsonAction := &Action{Account: raw.Account, ActionName: raw.Name, Data: raw.Data}
+		hexData, _ := json.Marshal(raw.HexData)
+		withoutABI := &Action{Account: raw.Account, ActionName: raw.Name, Data: hexData}
+		_, ok := withoutABI.DataField("owner")
+		assert.False(t, ok)
+
+		hexAction := &Action{Account: raw.Account, ActionName: raw.Name, Data: hexData,
+			abis: newFixtureABIStore()}
+		for _, path := range []string{"owner", "quotes.0.pair", "quotes.1.value"} {
+			expected, ok := jsonAction.DataField(path)
+			assert.True(t, ok)
+			actual, ok := hexAction.DataField(path)
+			assert.True(t, ok)
+			assert.Equal(t, expected, actual)
+		}
+		pair, _ := hexAction.DataField("quotes.0.pair")
+		assert.Equal(t, "eosusd", pair)
+		value, _ := hexAction.DataField("quotes.0.value")
+		assert.Equal(t, "25743", value)
+		_, ok = hexAction.DataField("quotes.9.pair")
+		assert.False(t, ok)
+	}
+}
+
+func TestGroupByDataField(t *testing.T) {
+	filepath := core.GetFixture(core.EOSValidBlocksFilename)
+	filter, err := core.ParseFilter(`receiver == "eosio.token" && name == "transfer" && data.to == "eidosonecoin"`)
+	assert.Nil(t, err)
+	by, err := core.GetActionProperties("data.memo:5", nil)
+	assert.Nil(t, err)
+	grouped, err := processor.CountActionsOverTime(New(), filepath,
+		uint64(0), uint64(0), core.TimeRange{}, filter, core.NewDuration(time.Hour), by)
+	assert.Nil(t, err)
+	assert.NotEmpty(t, grouped.Actions)
+}
@@ -1,10 +1,13 @@
 package eos
 
 import (
+	"bytes"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -19,13 +22,48 @@ var fastJson = jsoniter.ConfigCompatibleWithStandardLibrary
 const (
 	defaultProducerURL string = "https://api.main.alohaeos.com:443"
 	timeLayout         string = "2006-01-02T15:04:05.999"
+	slotDuration              = 500 * time.Millisecond
 )
 
+// EOS fetches and parses blocks from the chain API or, when Traces is set,
+// from the trace API, in which case actions include inline actions and notifications
 type EOS struct {
-	ProducerURL string
+	ProducerURL  string
+	ABIs         *ABIStore
+	ABIDirectory string
+	FetchABIs    bool
+	Traces       bool
+}
+
+// Options are the settings changing how blocks are fetched and parsed
+type Options struct {
+	Traces       bool
+	ABIDirectory string `json:",omitempty"`
+	FetchABIs    bool
+}
+
+func (e *EOS) Options() interface{} {
+	return Options{Traces: e.Traces, ABIDirectory: e.ABIDirectory, FetchABIs: e.FetchABIs}
+}
+
+// SetABIs sets the ABIs used to decode hex-encoded action data, read from directory
+// and, if fetch is set, fetched from the producer when missing
+func (e *EOS) SetABIs(directory string, fetch bool) {
+	e.ABIDirectory, e.FetchABIs = directory, fetch
+	switch {
+	case fetch:
+		e.ABIs = NewABIStore(directory, e.ProducerURL)
+	case directory != "":
+		e.ABIs = NewABIStore(directory, "")
+	default:
+		e.ABIs = nil
+	}
 }
 
 func (e *EOS) makeRequest(client *http.Client, blockNumber uint64) (*http.Response, error) {
+	if e.Traces {
+		return e.makeTraceRequest(client, blockNumber)
+	}
 	url := fmt.Sprintf("%s/v1/chain/get_block", e.ProducerURL)
 	data := fmt.Sprintf("{\"block_num_or_id\": %d}", blockNumber)
 	return client.Post(url, "application/json", strings.NewReader(data))
@@ -36,6 +74,14 @@ func (e *EOS) FetchData(filepath string, start, end uint64) error {
 	return fetcher.FetchHTTPData(filepath, context)
 }
 
+func (e *EOS) FetchBlock(number uint64) (core.Block, error) {
+	rawBlock, err := fetcher.FetchHTTPBlock(number, e.makeRequest)
+	if err != nil {
+		return nil, err
+	}
+	return e.ParseBlock(rawBlock)
+}
+
 type Action struct {
 	Account       string
 	ActionName    string `json:"name"`
@@ -43,7 +89,13 @@ type Action struct {
 		Actor      string
 		Permission string
 	}
-	Data json.RawMessage
+	Data        json.RawMessage
+	status      core.Status
+	fees        []core.Fee
+	abis        *ABIStore
+	blockNumber uint64
+	decoded     map[string]interface{}
+	decodeError error
 }
 
 type Transaction struct {
@@ -75,16 +127,42 @@ func (t *TrxOrString) UnmarshalJSON(b []byte) error {
 }
 
 type FullTransaction struct {
-	Status string
-	Trx    TrxOrString
+	Status        string
+	CPUUsageUs    uint64 `json:"cpu_usage_us"`
+	NetUsageWords uint64 `json:"net_usage_words"`
+	Trx           TrxOrString
+}
+
+// Fees returns the CPU time, in microseconds, and the network bandwidth,
+// in words of 8 bytes, billed to the transaction
+func (t *FullTransaction) Fees() []core.Fee {
+	return []core.Fee{
+		{Resource: "cpu_usage_us", Value: t.CPUUsageUs},
+		{Resource: "net_usage_words", Value: t.NetUsageWords},
+	}
+}
+
+// ExecutionStatus maps the status of the transaction receipt to a core.Status
+// Delayed transactions have not been executed yet and have an unknown status
+func (t *FullTransaction) ExecutionStatus() core.Status {
+	switch t.Status {
+	case "executed":
+		return core.StatusSuccess
+	case "soft_fail", "hard_fail", "expired":
+		return core.StatusFailure
+	default:
+		return core.StatusUnknown
+	}
 }
 
 type Block struct {
-	BlockNumber  uint64 `json:"block_num"`
-	Timestamp    string
-	parsedTime   time.Time
-	Transactions []FullTransaction
-	actions      []core.Action
+	BlockNumber   uint64 `json:"block_num"`
+	Timestamp     string
+	BlockProducer string `json:"producer"`
+	parsedTime    time.Time
+	Transactions  []FullTransaction
+	actions       []core.Action
+	abis          *ABIStore
 }
 
 func New() *EOS {
@@ -93,12 +171,18 @@ func New() *EOS {
 		producerURL = defaultProducerURL
 	}
 
-	return &EOS{
+	eos := &EOS{
 		ProducerURL: producerURL,
+		Traces:      os.Getenv("EOS_TRACES") != "",
 	}
+	eos.SetABIs(os.Getenv("EOS_ABI_DIR"), os.Getenv("EOS_FETCH_ABIS") != "")
+	return eos
 }
 
 func (e *EOS) ParseBlock(rawBlock []byte) (core.Block, error) {
+	if e.Traces {
+		return e.parseTraceBlock(rawBlock)
+	}
 	var block Block
 	if err := fastJson.Unmarshal(rawBlock, &block); err != nil {
 		return nil, err
@@ -108,11 +192,15 @@ func (e *EOS) ParseBlock(rawBlock []byte) (core.Block, error) {
 		return nil, err
 	}
 	block.parsedTime = parsedTime
+	block.abis = e.ABIs
 	return &block, fastJson.Unmarshal(rawBlock, &block)
 }
 
 func (e *EOS) EmptyBlock() core.Block {
-	return &Block{}
+	if e.Traces {
+		return &TraceBlock{abis: e.ABIs}
+	}
+	return &Block{abis: e.ABIs}
 }
 
 func (b *Block) Number() uint64 {
@@ -123,24 +211,68 @@ func (b *Block) Time() time.Time {
 	return b.parsedTime
 }
 
+func (b *Block) Producer() string {
+	return b.BlockProducer
+}
+
+func (b *Block) SlotDuration() time.Duration {
+	return slotDuration
+}
+
 func (b *Block) TransactionsCount() int {
 	return len(b.Transactions)
 }
 
+// ListActions returns the actions of all the transactions, each pointing
+// to its own entry in the transaction rather than to a shared loop variable
 func (b *Block) ListActions() []core.Action {
 	if len(b.actions) > 0 {
 		return b.actions
 	}
 	var actions []core.Action
 	for _, transaction := range b.Transactions {
-		for _, action := range transaction.Trx.Transaction.Actions {
-			actions = append(actions, &action)
+		status := transaction.ExecutionStatus()
+		transactionActions := transaction.Trx.Transaction.Actions
+		for i := range transactionActions {
+			transactionActions[i].status = status
+			transactionActions[i].abis = b.abis
+			transactionActions[i].blockNumber = b.BlockNumber
+			actions = append(actions, &transactionActions[i])
+		}
+		if len(transactionActions) > 0 {
+			transactionActions[0].fees = transaction.Fees()
 		}
 	}
 	b.actions = actions
 	return actions
 }
 
+// ListTransactions returns the transactions of the block, including
+// deferred transactions which are only given by their ID and have no actions
+// The signer is the first authorizer of the first action
+func (b *Block) ListTransactions() []core.Transaction {
+	b.ListActions()
+	transactions := make([]core.Transaction, len(b.Transactions))
+	for i, transaction := range b.Transactions {
+		transactionActions := transaction.Trx.Transaction.Actions
+		actions := make([]core.Action, len(transactionActions))
+		for j := range transactionActions {
+			actions[j] = &transactionActions[j]
+		}
+		var signer string
+		if len(actions) > 0 {
+			signer = actions[0].Sender()
+		}
+		transactions[i] = core.Transaction{
+			ID:      transaction.Trx.Id,
+			Index:   i,
+			Signer:  signer,
+			Actions: actions,
+		}
+	}
+	return transactions
+}
+
 func (a *Action) Name() string {
 	return a.ActionName
 }
@@ -155,3 +287,128 @@ func (a *Action) Sender() string {
 func (a *Action) Receiver() string {
 	return a.Account
 }
+
+func (a *Action) Status() core.Status {
+	return a.status
+}
+
+// Fees returns the resources used by the transaction of the action
+// for its first action and nothing for the other actions
+func (a *Action) Fees() []core.Fee {
+	return a.fees
+}
+
+// DecodedData returns the fields of the action data, which are either
+// given as JSON or decoded from hex using the ABI of the contract
+func (a *Action) DecodedData() (map[string]interface{}, error) {
+	if a.decoded != nil || a.decodeError != nil {
+		return a.decoded, a.decodeError
+	}
+	a.decoded, a.decodeError = a.decodeData()
+	return a.decoded, a.decodeError
+}
+
+func (a *Action) decodeData() (map[string]interface{}, error) {
+	if len(a.Data) > 0 && a.Data[0] == '{' {
+		var decoded map[string]interface{}
+		decoder := fastJson.NewDecoder(bytes.NewReader(a.Data))
+		decoder.UseNumber()
+		if err := decoder.Decode(&decoded); err != nil {
+			return nil, err
+		}
+		return decoded, nil
+	}
+	var hexData string
+	if err := fastJson.Unmarshal(a.Data, &hexData); err != nil {
+		return nil, err
+	}
+	data, err := hex.DecodeString(hexData)
+	if err != nil {
+		return nil, err
+	}
+	if a.abis == nil {
+		return nil, fmt.Errorf("no ABIs to decode %s::%s", a.Account, a.ActionName)
+	}
+	abi, err := a.abis.Get(a.Account, a.blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	return abi.DecodeAction(a.ActionName, data)
+}
+
+// UnmarshalData unmarshals the action data, decoded if needed, into value
+func (a *Action) UnmarshalData(value interface{}) error {
+	if len(a.Data) > 0 && a.Data[0] == '{' {
+		return fastJson.Unmarshal(a.Data, value)
+	}
+	decoded, err := a.DecodedData()
+	if err != nil {
+		return err
+	}
+	rawData, err := json.Marshal(decoded)
+	if err != nil {
+		return err
+	}
+	return fastJson.Unmarshal(rawData, value)
+}
+
+// DataField returns the value of a field of the decoded data
+// Nested fields and array elements are separated by dots, e.g. quotes.0.pair
+func (a *Action) DataField(path string) (string, bool) {
+	decoded, err := a.DecodedData()
+	if err != nil {
+		return "", false
+	}
+	var value interface{} = decoded
+	for _, key := range strings.Split(path, ".") {
+		switch current := value.(type) {
+		case map[string]interface{}:
+			field, ok := current[key]
+			if !ok {
+				return "", false
+			}
+			value = field
+		case []interface{}:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(current) {
+				return "", false
+			}
+			value = current[index]
+		default:
+			return "", false
+		}
+	}
+	switch value := value.(type) {
+	case nil:
+		return "", true
+	case string:
+		return value, true
+	case map[string]interface{}, []interface{}:
+		encoded, err := json.Marshal(value)
+		return string(encoded), err == nil
+	default:
+		return fmt.Sprint(value), true
+	}
+}
+
+// Transfer returns the transfer of transfer actions following the
+// eosio.token format, where the issuer of the asset is the contract
+// Actions of failed transactions do not transfer anything
+func (a *Action) Transfer() (core.Transfer, bool) {
+	if a.ActionName != "transfer" || a.status == core.StatusFailure {
+		return core.Transfer{}, false
+	}
+	var transferData TransferData
+	if err := a.UnmarshalData(&transferData); err != nil {
+		return core.Transfer{}, false
+	}
+	quantity, symbol, err := parseTransferQuantity(transferData.Quantity)
+	if err != nil {
+		return core.Transfer{}, false
+	}
+	amount, err := core.NewAmount(quantity, symbol, a.Account)
+	if err != nil {
+		return core.Transfer{}, false
+	}
+	return core.Transfer{From: transferData.From, To: transferData.To, Amount: amount}, true
+}
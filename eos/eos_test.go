@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/danhper/blockchain-analyzer/core"
+	"github.com/danhper/blockchain-analyzer/processor"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -17,6 +18,8 @@ func TestParseBlock(t *testing.T) {
 	assert.Equal(t, 8, block.TransactionsCount())
 	expectedTime := time.Date(2020, time.Month(5), 16, 0, 10, 43, 0, time.UTC)
 	assert.Equal(t, expectedTime, block.Time())
+	assert.Equal(t, "bitfinexeos1", block.Producer())
+	assert.Equal(t, 500*time.Millisecond, block.(core.SlotBlock).SlotDuration())
 }
 
 func TestParseBlockWithoutTrx(t *testing.T) {
@@ -34,3 +37,139 @@ func TestListActions(t *testing.T) {
 	actions := block.ListActions()
 	assert.Len(t, actions, 176)
 }
+
+func TestListActionsDistinct(t *testing.T) {
+	for _, rawBlock := range core.ReadAllBlocks("eos")[:10] {
+		block, err := New().ParseBlock(rawBlock)
+		assert.Nil(t, err)
+		eosBlock := block.(*Block)
+		var expected []Action
+		for _, transaction := range eosBlock.Transactions {
+			expected = append(expected, transaction.Trx.Transaction.Actions...)
+		}
+		actions := block.ListActions()
+		seen := make(map[core.Action]bool)
+		if assert.Len(t, actions, len(expected)) {
+			for i, action := range actions {
+				assert.False(t, seen[action], "action %d of block %d is listed twice", i, block.Number())
+				seen[action] = true
+				assert.Equal(t, expected[i].Account, action.Receiver())
+				assert.Equal(t, expected[i].ActionName, action.Name())
+				assert.Equal(t, string(expected[i].Data), string(action.(*Action).Data))
+			}
+		}
+	}
+}
+
+func TestCountProducersOverTime(t *testing.T) {
+	filepath := core.GetFixture(core.EOSValidBlocksFilename)
+	producers, err := processor.CountProducersOverTime(New(), filepath,
+		uint64(0), uint64(0), core.TimeRange{}, core.NewDuration(time.Hour), []int{1})
+	assert.Nil(t, err)
+	stats, total := producers.Stats()
+	assert.Len(t, stats, 1)
+	assert.Equal(t, uint64(100), total.BlocksCount)
+	assert.Len(t, total.Blocks, 9)
+	assert.Equal(t, uint64(12), total.Blocks["newdex.bp"])
+	assert.Equal(t, uint64(0), total.MissedSlots)
+	assert.Equal(t, 5, total.Concentration.NakamotoCoefficient)
+}
+
+func TestActionTransfer(t *testing.T) {
+	rawBlock := core.ReadAllBlocks("eos")[0]
+	block, _ := New().ParseBlock(rawBlock)
+	names := make(map[string]int)
+	volume := core.NewTransferVolume(core.Duration{})
+	volume.AddBlock(block)
+	for _, action := range block.ListActions() {
+		names[action.Name()]++
+	}
+	assert.Equal(t, 170, names["transfer"])
+	assert.Equal(t, 1, names["reveal"])
+
+	var transferAction core.Action
+	for _, action := range block.ListActions() {
+		if _, ok := core.GetTransfer(action); !ok {
+			assert.NotEqual(t, "transfer", action.Name())
+		} else if transferAction == nil {
+			transferAction = action
+		}
+	}
+	transfer, ok := core.GetTransfer(transferAction)
+	assert.True(t, ok)
+	assert.Equal(t, "iamdifferent", transfer.From)
+	assert.Equal(t, "eidosonecoin", transfer.To)
+	assert.Equal(t, "0.0001 EOS@eosio.token", transfer.Amount.String())
+
+	total := volume.Total("EOS@eosio.token")
+	assert.Equal(t, uint64(170), total.Count)
+	assert.Equal(t, "0.017", core.FormatDecimal(total.Amount))
+}
+
+func TestActionStatus(t *testing.T) {
+	filepath := core.GetFixture(core.EOSValidBlocksFilename)
+	failureRate, err := processor.ComputeFailureRateOverTime(New(), filepath,
+		uint64(0), uint64(0), core.TimeRange{}, nil, core.NewDuration(time.Hour))
+	assert.Nil(t, err)
+	assert.Equal(t, failureRate.Total.ActionsCount, failureRate.Total.SuccessCount+
+		failureRate.Total.FailureCount+failureRate.Total.UnknownCount)
+	assert.Greater(t, failureRate.Total.SuccessCount, uint64(0))
+
+	assert.Equal(t, core.StatusSuccess, (&FullTransaction{Status: "executed"}).ExecutionStatus())
+	assert.Equal(t, core.StatusFailure, (&FullTransaction{Status: "soft_fail"}).ExecutionStatus())
+	assert.Equal(t, core.StatusUnknown, (&FullTransaction{Status: "delayed"}).ExecutionStatus())
+}
+
+func TestActionFees(t *testing.T) {
+	rawBlock := core.ReadAllBlocks("eos")[0]
+	block, _ := New().ParseBlock(rawBlock)
+	fees := core.NewFeesOverTime(core.NewDuration(time.Hour))
+	fees.AddBlock(block)
+	cpu := fees.Total["cpu_usage_us"].Summary()
+	assert.Equal(t, uint64(8), cpu.Count)
+	assert.Equal(t, uint64(15220), cpu.Total)
+	assert.Equal(t, uint64(1695), fees.Total["net_usage_words"].Summary().Total)
+}
+
+func TestListTransactions(t *testing.T) {
+	rawBlock := core.ReadAllBlocks("eos")[0]
+	block, _ := New().ParseBlock(rawBlock)
+	transactions := block.ListTransactions()
+	assert.Len(t, transactions, 8)
+	assert.Equal(t, "dae758e9d3ab2ffec577d0b09fb6b5f6a2127372fdc3e611bc3bd72c5a3d1a7c", transactions[0].ID)
+	assert.Equal(t, "teamgreymass", transactions[0].Signer)
+	actionsCount := 0
+	for i, transaction := range transactions {
+		assert.Equal(t, i, transaction.Index)
+		actionsCount += len(transaction.Actions)
+	}
+	assert.Equal(t, len(block.ListActions()), actionsCount)
+
+	filepath := core.GetFixture(core.EOSValidBlocksFilename)
+	actionsPerTransaction, err := processor.ComputeActionsPerTransaction(New(), filepath,
+		uint64(0), uint64(0), core.TimeRange{}, nil, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(2016), actionsPerTransaction.Distribution.Summary().Count)
+	assert.Equal(t, uint64(651), actionsPerTransaction.MultiActionCount)
+	assert.Equal(t, []core.TransactionPattern{{Pattern: "transfer*90", Count: 386}},
+		actionsPerTransaction.Patterns())
+}
+
+func TestSetABIs(t *testing.T) {
+	eos := &EOS{ProducerURL: "http://localhost:8888"}
+	eos.SetABIs("", false)
+	assert.Nil(t, eos.ABIs)
+
+	eos.SetABIs(core.GetFixture(core.EOSABIsDirectory), false)
+	assert.Equal(t, "", eos.ABIs.NodeURL)
+
+	eos.SetABIs("", true)
+	assert.Equal(t, "http://localhost:8888", eos.ABIs.NodeURL)
+	assert.Equal(t, Options{FetchABIs: true}, eos.Options())
+
+	eos.Traces = true
+	config := processor.BulkConfig{Pattern: core.GetFixture(core.EOSTracesFilename)}
+	result, err := processor.RunBulkActions(eos, config)
+	assert.Nil(t, err)
+	assert.Equal(t, Options{Traces: true, FetchABIs: true}, result["Config"].(processor.BulkConfig).Blockchain)
+}
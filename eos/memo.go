@@ -0,0 +1,238 @@
+package eos
+
+import (
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/danhper/blockchain-analyzer/core"
+	"github.com/danhper/blockchain-analyzer/processor"
+)
+
+const DefaultMinDepositIDs = 100
+
+type MemoCategory string
+
+const (
+	MemoEmpty     MemoCategory = "empty"
+	MemoDepositID MemoCategory = "deposit-id"
+	MemoURL       MemoCategory = "url"
+	MemoAirdrop   MemoCategory = "airdrop"
+	MemoText      MemoCategory = "text"
+)
+
+var (
+	depositIDPattern = regexp.MustCompile(`^[0-9]+$`)
+	// urlPattern is a heuristic: besides explicit URLs, it only matches bare domains
+	// with one of the listed TLDs, common in EOS dapp memos, so e.g. dapp.network
+	// is not detected while any text containing e.g. name.me is
+	urlPattern = regexp.MustCompile(
+		`(?i)(https?://|www\.)\S+|\b[a-z0-9-]+\.(com|io|net|org|one|me|co|app|xyz|best|top|vip|fun|games?)\b`)
+	// airdropPatterns are templates commonly used by airdrops and referral rewards
+	airdropPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`(?i)air ?drop`),
+		regexp.MustCompile(`(?i)give ?away`),
+		regexp.MustCompile(`(?i)\bfree\b.*\btokens?\b`),
+		regexp.MustCompile(`(?i)\b(daily|invit\w*|referral|sign ?up) rewards?\b`),
+		regexp.MustCompile(`(?i)\brewards? for invit\w*`),
+		regexp.MustCompile(`(?i)^claim\b`),
+	}
+)
+
+// ClassifyMemo returns the category of a transfer memo, airdrop templates
+// taking precedence over URLs as airdrop memos often advertise a website
+func ClassifyMemo(memo string) MemoCategory {
+	memo = strings.TrimSpace(memo)
+	switch {
+	case memo == "":
+		return MemoEmpty
+	case depositIDPattern.MatchString(memo):
+		return MemoDepositID
+	}
+	for _, pattern := range airdropPatterns {
+		if pattern.MatchString(memo) {
+			return MemoAirdrop
+		}
+	}
+	if urlPattern.MatchString(memo) {
+		return MemoURL
+	}
+	return MemoText
+}
+
+// ReceiverMemos counts the memos of the transfers received by an account
+type ReceiverMemos struct {
+	Account        string
+	TransfersCount uint64
+	Categories     map[MemoCategory]uint64
+	OverTime       map[time.Time]map[MemoCategory]uint64
+	depositIDs     map[string]bool
+	depositSenders map[string]bool
+}
+
+func newReceiverMemos(account string) *ReceiverMemos {
+	return &ReceiverMemos{
+		Account:        account,
+		Categories:     make(map[MemoCategory]uint64),
+		OverTime:       make(map[time.Time]map[MemoCategory]uint64),
+		depositIDs:     make(map[string]bool),
+		depositSenders: make(map[string]bool),
+	}
+}
+
+func (r *ReceiverMemos) DistinctDepositIDs() int {
+	return len(r.depositIDs)
+}
+
+func (r *ReceiverMemos) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"Account":            r.Account,
+		"TransfersCount":     r.TransfersCount,
+		"Categories":         r.Categories,
+		"OverTime":           r.OverTime,
+		"DistinctDepositIDs": len(r.depositIDs),
+		"DepositSenders":     len(r.depositSenders),
+	})
+}
+
+// MemoStats classifies the memos of transfers, overall and per receiver over time
+// Receivers with at least minDepositIDs distinct numeric memos are likely exchange
+// hot wallets, where the memo identifies the account of the user on the exchange
+type MemoStats struct {
+	Duration      core.Duration
+	Total         map[MemoCategory]uint64
+	OverTime      map[time.Time]map[MemoCategory]uint64
+	receivers     map[string]*ReceiverMemos
+	topReceivers  int
+	minDepositIDs int
+}
+
+func NewMemoStats(duration core.Duration) *MemoStats {
+	return &MemoStats{
+		Duration:      duration,
+		Total:         make(map[MemoCategory]uint64),
+		OverTime:      make(map[time.Time]map[MemoCategory]uint64),
+		receivers:     make(map[string]*ReceiverMemos),
+		topReceivers:  core.DefaultNestedResults,
+		minDepositIDs: DefaultMinDepositIDs,
+	}
+}
+
+// SetTopReceivers sets the number of receivers with the most transfers to output, 0 for unlimited
+func (m *MemoStats) SetTopReceivers(topReceivers int) *MemoStats {
+	m.topReceivers = topReceivers
+	return m
+}
+
+func (m *MemoStats) SetMinDepositIDs(minDepositIDs int) *MemoStats {
+	m.minDepositIDs = minDepositIDs
+	return m
+}
+
+func incrementCategory(counts map[time.Time]map[MemoCategory]uint64, group time.Time, category MemoCategory) {
+	if _, ok := counts[group]; !ok {
+		counts[group] = make(map[MemoCategory]uint64)
+	}
+	counts[group][category]++
+}
+
+func (m *MemoStats) AddBlock(block core.Block) {
+	group := m.Duration.Truncate(block.Time())
+	for _, action := range block.ListActions() {
+		transfer, ok := core.GetTransfer(action)
+		if !ok {
+			continue
+		}
+		memo := core.GetDataField(action, "memo")
+		category := ClassifyMemo(memo)
+		m.Total[category]++
+		incrementCategory(m.OverTime, group, category)
+
+		receiver, ok := m.receivers[transfer.To]
+		if !ok {
+			receiver = newReceiverMemos(transfer.To)
+			m.receivers[transfer.To] = receiver
+		}
+		receiver.TransfersCount++
+		receiver.Categories[category]++
+		incrementCategory(receiver.OverTime, group, category)
+		if category == MemoDepositID {
+			receiver.depositIDs[strings.TrimSpace(memo)] = true
+			receiver.depositSenders[transfer.From] = true
+		}
+	}
+}
+
+func sortReceivers(receivers []*ReceiverMemos, less func(a, b *ReceiverMemos) bool) {
+	sort.Slice(receivers, func(i, j int) bool {
+		a, b := receivers[i], receivers[j]
+		if less(a, b) {
+			return true
+		}
+		if less(b, a) {
+			return false
+		}
+		return a.Account < b.Account
+	})
+}
+
+// Receivers returns the receivers with the most transfers
+func (m *MemoStats) Receivers() []*ReceiverMemos {
+	receivers := make([]*ReceiverMemos, 0, len(m.receivers))
+	for _, receiver := range m.receivers {
+		receivers = append(receivers, receiver)
+	}
+	sortReceivers(receivers, func(a, b *ReceiverMemos) bool {
+		return a.TransfersCount > b.TransfersCount
+	})
+	if m.topReceivers > 0 && len(receivers) > m.topReceivers {
+		receivers = receivers[:m.topReceivers]
+	}
+	return receivers
+}
+
+// LikelyExchanges returns the receivers with at least minDepositIDs
+// distinct numeric memos, sorted by number of distinct memos
+func (m *MemoStats) LikelyExchanges() []*ReceiverMemos {
+	var exchanges []*ReceiverMemos
+	for _, receiver := range m.receivers {
+		if receiver.DistinctDepositIDs() >= m.minDepositIDs {
+			exchanges = append(exchanges, receiver)
+		}
+	}
+	sortReceivers(exchanges, func(a, b *ReceiverMemos) bool {
+		return a.DistinctDepositIDs() > b.DistinctDepositIDs()
+	})
+	return exchanges
+}
+
+func (m *MemoStats) MarshalJSON() ([]byte, error) {
+	exchanges := make([]string, 0)
+	for _, receiver := range m.LikelyExchanges() {
+		exchanges = append(exchanges, receiver.Account)
+	}
+	return json.Marshal(map[string]interface{}{
+		"Duration":        m.Duration,
+		"Total":           m.Total,
+		"OverTime":        m.OverTime,
+		"ReceiversCount":  len(m.receivers),
+		"Receivers":       m.Receivers(),
+		"LikelyExchanges": exchanges,
+	})
+}
+
+func (m *MemoStats) Result() interface{} {
+	return m
+}
+
+// ComputeMemoStats classifies the memos of the transfers of the matching actions
+func ComputeMemoStats(blockchain core.Blockchain, globPattern string,
+	start, end uint64, timeRange core.TimeRange, filter *core.Filter,
+	duration core.Duration, topReceivers, minDepositIDs int,
+) (*MemoStats, error) {
+	result := NewMemoStats(duration).SetTopReceivers(topReceivers).SetMinDepositIDs(minDepositIDs)
+	err := processor.AggregateBlocks(blockchain, globPattern, start, end, timeRange, filter, result)
+	return result, err
+}
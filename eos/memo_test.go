@@ -0,0 +1,70 @@
+package eos
+
+import (
+	"testing"
+	"time"
+
+	"github.com/danhper/blockchain-analyzer/core"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyMemo(t *testing.T) {
+	cases := map[string]MemoCategory{
+		"":             MemoEmpty,
+		" ":            MemoEmpty,
+		"13253":        MemoDepositID,
+		"love from tp": MemoText,
+		"a277345":      MemoText,
+		"Felix bitball referrel from f1f312411241 - https://felixball.io": MemoURL,
+		"visit dapp.one now": MemoURL,
+		"你的每日邀请&注册奖励 / Your daily reward for inviting users":    MemoAirdrop,
+		"EOS AirDrop: 100 free tokens, see https://example.com": MemoAirdrop,
+	}
+	for memo, expected := range cases {
+		assert.Equal(t, expected, ClassifyMemo(memo), memo)
+	}
+}
+
+// bare domains are only detected for the TLDs of urlPattern
+func TestClassifyMemoURLHeuristic(t *testing.T) {
+	cases := map[string]MemoCategory{
+		"see dapp.network":         MemoText,
+		"join site.club":           MemoText,
+		"version 1.5 released":     MemoText,
+		"meet at 10.30":            MemoText,
+		"hello.world":              MemoText,
+		"comeback.common":          MemoText,
+		"www.dapp.network":         MemoURL,
+		"http://dapp.network/play": MemoURL,
+		// false positives of the fixed TLD list
+		"ask x.co":    MemoURL,
+		"thanks.me !": MemoURL,
+	}
+	for memo, expected := range cases {
+		assert.Equal(t, expected, ClassifyMemo(memo), memo)
+	}
+}
+
+func TestComputeMemoStats(t *testing.T) {
+	memoStats, err := ComputeMemoStats(New(), core.GetFixture(core.EOSValidBlocksFilename),
+		uint64(0), uint64(0), core.TimeRange{}, nil, core.NewDuration(time.Hour), 2, 5)
+	assert.Nil(t, err)
+	assert.Equal(t, map[MemoCategory]uint64{
+		MemoEmpty: 34874, MemoText: 7768, MemoDepositID: 391, MemoURL: 70, MemoAirdrop: 29,
+	}, memoStats.Total)
+
+	receivers := memoStats.Receivers()
+	if assert.Len(t, receivers, 2) {
+		assert.Equal(t, "eidosonecoin", receivers[0].Account)
+		assert.Equal(t, uint64(42399), receivers[0].TransfersCount)
+		assert.Equal(t, uint64(386), receivers[0].Categories[MemoDepositID])
+		assert.Equal(t, 10, receivers[0].DistinctDepositIDs())
+		assert.Equal(t, "eosiopowcoin", receivers[1].Account)
+	}
+
+	exchanges := memoStats.LikelyExchanges()
+	if assert.Len(t, exchanges, 1) {
+		assert.Equal(t, "eidosonecoin", exchanges[0].Account)
+	}
+	assert.Empty(t, memoStats.SetMinDepositIDs(DefaultMinDepositIDs).LikelyExchanges())
+}
@@ -0,0 +1,261 @@
+package eos
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/danhper/blockchain-analyzer/core"
+)
+
+func (e *EOS) makeTraceRequest(client *http.Client, blockNumber uint64) (*http.Response, error) {
+	url := fmt.Sprintf("%s/v1/trace_api/get_block", e.ProducerURL)
+	data := fmt.Sprintf("{\"block_num\": %d}", blockNumber)
+	return client.Post(url, "application/json", strings.NewReader(data))
+}
+
+type TraceAuthorization struct {
+	Account    string
+	Permission string
+}
+
+// missingOrdinalsWarning is only logged once, as all the blocks
+// of a data set usually come from the same source
+var missingOrdinalsWarning sync.Once
+
+// ActionTrace is an action executed by a transaction, including inline actions
+// and notifications. Parent information is given by the ordinals, as in the
+// action traces of nodeos. The trace API of nodeos does not return ordinals,
+// so in transactions with several actions and without ordinals, only the first
+// action is known to be top-level and the others have an unknown depth
+type ActionTrace struct {
+	ActionOrdinal        uint32 `json:"action_ordinal"`
+	CreatorActionOrdinal uint32 `json:"creator_action_ordinal"`
+	ActionReceiver       string `json:"receiver"`
+	Account              string
+	ActionName           string `json:"action"`
+	Authorization        []TraceAuthorization
+	Data                 json.RawMessage
+	Params               json.RawMessage
+	action               Action
+	depth                int
+}
+
+type TransactionTrace struct {
+	Id            string
+	Status        string
+	CPUUsageUs    uint64 `json:"cpu_usage_us"`
+	NetUsageWords uint64 `json:"net_usage_words"`
+	Actions       []ActionTrace
+}
+
+// TraceBlock is a block as returned by the get_block endpoint of the trace API
+type TraceBlock struct {
+	BlockNumber   uint64 `json:"number"`
+	Timestamp     string
+	BlockProducer string `json:"producer"`
+	Transactions  []TransactionTrace
+	parsedTime    time.Time
+	actions       []core.Action
+	abis          *ABIStore
+}
+
+func parseTimestamp(timestamp string) (time.Time, error) {
+	parsedTime, err := time.Parse(time.RFC3339Nano, timestamp)
+	if err != nil {
+		return time.Parse(timeLayout, timestamp)
+	}
+	return parsedTime, nil
+}
+
+func (e *EOS) parseTraceBlock(rawBlock []byte) (core.Block, error) {
+	var block TraceBlock
+	if err := fastJson.Unmarshal(rawBlock, &block); err != nil {
+		return nil, err
+	}
+	parsedTime, err := parseTimestamp(block.Timestamp)
+	if err != nil {
+		return nil, err
+	}
+	block.parsedTime = parsedTime
+	block.abis = e.ABIs
+	return &block, nil
+}
+
+func (b *TraceBlock) Number() uint64 {
+	return b.BlockNumber
+}
+
+func (b *TraceBlock) Time() time.Time {
+	if b.parsedTime.IsZero() {
+		b.parsedTime, _ = parseTimestamp(b.Timestamp)
+	}
+	return b.parsedTime
+}
+
+func (b *TraceBlock) Producer() string {
+	return b.BlockProducer
+}
+
+func (b *TraceBlock) SlotDuration() time.Duration {
+	return slotDuration
+}
+
+func (b *TraceBlock) TransactionsCount() int {
+	return len(b.Transactions)
+}
+
+// ListActions returns all the actions executed in the block, in execution order
+func (b *TraceBlock) ListActions() []core.Action {
+	if len(b.actions) > 0 {
+		return b.actions
+	}
+	var actions []core.Action
+	for _, transaction := range b.Transactions {
+		fullTransaction := FullTransaction{
+			Status:        transaction.Status,
+			CPUUsageUs:    transaction.CPUUsageUs,
+			NetUsageWords: transaction.NetUsageWords,
+		}
+		status := fullTransaction.ExecutionStatus()
+		traces := transaction.Actions
+		nested := hasOrdinals(traces)
+		if !nested {
+			missingOrdinalsWarning.Do(func() {
+				log.Printf("block %d: action traces have no action_ordinal, "+
+					"the depth of actions after the first of each transaction is unknown", b.BlockNumber)
+			})
+		}
+		depths := make(map[uint32]int)
+		for i := range traces {
+			trace := &traces[i]
+			if !nested && i > 0 {
+				trace.depth = core.UnknownDepth
+			} else if trace.CreatorActionOrdinal > 0 {
+				trace.depth = depths[trace.CreatorActionOrdinal] + 1
+			}
+			if trace.ActionOrdinal > 0 {
+				depths[trace.ActionOrdinal] = trace.depth
+			}
+			trace.action = trace.toAction(status, b.abis, b.BlockNumber)
+			if i == 0 {
+				trace.action.fees = fullTransaction.Fees()
+			}
+			actions = append(actions, trace)
+		}
+	}
+	b.actions = actions
+	return actions
+}
+
+// hasOrdinals returns true if the nesting of the actions is known, either from their
+// ordinals or because the transaction has a single action
+func hasOrdinals(traces []ActionTrace) bool {
+	if len(traces) <= 1 {
+		return true
+	}
+	for _, trace := range traces {
+		if trace.ActionOrdinal == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (b *TraceBlock) ListTransactions() []core.Transaction {
+	b.ListActions()
+	transactions := make([]core.Transaction, len(b.Transactions))
+	for i, transaction := range b.Transactions {
+		actions := make([]core.Action, len(transaction.Actions))
+		for j := range transaction.Actions {
+			actions[j] = &transaction.Actions[j]
+		}
+		var signer string
+		if len(actions) > 0 {
+			signer = actions[0].Sender()
+		}
+		transactions[i] = core.Transaction{
+			ID:      transaction.Id,
+			Index:   i,
+			Signer:  signer,
+			Actions: actions,
+		}
+	}
+	return transactions
+}
+
+// toAction converts the trace to an action, using the decoded params if any
+func (t *ActionTrace) toAction(status core.Status, abis *ABIStore, blockNumber uint64) Action {
+	action := Action{
+		Account:     t.Account,
+		ActionName:  t.ActionName,
+		Data:        bytes.TrimSpace(t.Data),
+		status:      status,
+		abis:        abis,
+		blockNumber: blockNumber,
+	}
+	if params := bytes.TrimSpace(t.Params); len(params) > 0 && params[0] == '{' {
+		action.Data = params
+	}
+	for _, authorization := range t.Authorization {
+		action.Authorization = append(action.Authorization, struct {
+			Actor      string
+			Permission string
+		}{authorization.Account, authorization.Permission})
+	}
+	return action
+}
+
+func (t *ActionTrace) Name() string {
+	return t.ActionName
+}
+
+func (t *ActionTrace) Sender() string {
+	return t.action.Sender()
+}
+
+// Receiver returns the account executing the action, which is
+// the notified account for notifications
+func (t *ActionTrace) Receiver() string {
+	return t.ActionReceiver
+}
+
+func (t *ActionTrace) Status() core.Status {
+	return t.action.status
+}
+
+func (t *ActionTrace) Fees() []core.Fee {
+	return t.action.fees
+}
+
+func (t *ActionTrace) Depth() int {
+	return t.depth
+}
+
+// Parent returns the ordinal of the action which created this action, 0 for top-level
+// actions and for actions with an unknown depth
+func (t *ActionTrace) Parent() uint32 {
+	return t.CreatorActionOrdinal
+}
+
+func (t *ActionTrace) IsNotification() bool {
+	return t.ActionReceiver != t.Account
+}
+
+func (t *ActionTrace) DataField(path string) (string, bool) {
+	return t.action.DataField(path)
+}
+
+// Transfer returns the transfer of the action, notifications
+// being ignored so that each transfer is only counted once
+func (t *ActionTrace) Transfer() (core.Transfer, bool) {
+	if t.IsNotification() {
+		return core.Transfer{}, false
+	}
+	return t.action.Transfer()
+}
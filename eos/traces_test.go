@@ -0,0 +1,148 @@
+package eos
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/danhper/blockchain-analyzer/core"
+	"github.com/danhper/blockchain-analyzer/processor"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTracesEOS() *EOS {
+	return &EOS{
+		Traces: true,
+		ABIs:   NewABIStore(core.GetFixture(core.EOSABIsDirectory), ""),
+	}
+}
+
+func readTraceBlocks(t *testing.T) []core.Block {
+	content, err := ioutil.ReadFile(core.GetFixture(core.EOSTracesFilename))
+	assert.Nil(t, err)
+	var blocks []core.Block
+	eos := newTracesEOS()
+	for _, rawBlock := range bytes.Split(bytes.TrimSpace(content), []byte{'\n'}) {
+		block, err := eos.ParseBlock(rawBlock)
+		assert.Nil(t, err)
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
+
+func TestParseTraceBlock(t *testing.T) {
+	blocks := readTraceBlocks(t)
+	assert.Len(t, blocks, 2)
+	block := blocks[0]
+	assert.Equal(t, uint64(1000), block.Number())
+	assert.Equal(t, 2, block.TransactionsCount())
+	assert.Equal(t, time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC), block.Time())
+	assert.Equal(t, "eosnewyorkio", block.Producer())
+	assert.IsType(t, &TraceBlock{}, newTracesEOS().EmptyBlock())
+}
+
+func TestTraceListActions(t *testing.T) {
+	block := readTraceBlocks(t)[0]
+	actions := block.ListActions()
+	assert.Len(t, actions, 8)
+
+	expectedDepths := []int{0, 0, 0, 1, 2, 2, 2, 0}
+	expectedNotifications := []bool{false, true, true, false, false, true, true, false}
+	for i, action := range actions {
+		trace := action.(*ActionTrace)
+		assert.Equal(t, expectedDepths[i], trace.Depth(), "action %d", i)
+		assert.Equal(t, expectedNotifications[i], trace.IsNotification(), "action %d", i)
+	}
+	resolve := actions[3].(*ActionTrace)
+	assert.Equal(t, uint32(3), resolve.Parent())
+	assert.Equal(t, "resolve", resolve.Name())
+	assert.Equal(t, "dice", resolve.Sender())
+	assert.Equal(t, "dice", resolve.Receiver())
+	assert.Equal(t, core.StatusSuccess, resolve.Status())
+	assert.Equal(t, "alice", core.GetDataField(resolve, "player"))
+
+	var transfers []core.Transfer
+	for _, action := range actions {
+		if transfer, ok := core.GetTransfer(action); ok {
+			transfers = append(transfers, transfer)
+		}
+	}
+	assert.Len(t, transfers, 3)
+	assert.Equal(t, "dice", transfers[1].From)
+	assert.Equal(t, "alice", transfers[1].To)
+
+	// without ordinals, the data is decoded using the ABI
+	assert.Equal(t, "bet", core.GetDataField(actions[7], "memo"))
+	assert.Equal(t, "carol", transfers[2].From)
+
+	fees := core.GetFees(actions[0])
+	assert.Equal(t, []core.Fee{{Resource: "cpu_usage_us", Value: 300}, {Resource: "net_usage_words", Value: 20}}, fees)
+	assert.Empty(t, core.GetFees(actions[1]))
+
+	failed := readTraceBlocks(t)[1].ListActions()
+	assert.Equal(t, core.StatusFailure, failed[0].Status())
+}
+
+// the fixture follows the format of the trace API plugin of nodeos,
+// which sorts actions by global sequence and does not return ordinals
+func TestTraceListActionsWithoutOrdinals(t *testing.T) {
+	content, err := ioutil.ReadFile(core.GetFixture(core.EOSTraceAPIBlockFilename))
+	assert.Nil(t, err)
+	block, err := newTracesEOS().ParseBlock(bytes.TrimSpace(content))
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(120889180), block.Number())
+	actions := block.ListActions()
+	assert.Len(t, actions, 9)
+
+	unknown := core.UnknownDepth
+	expectedDepths := []int{0, unknown, unknown, 0, unknown, unknown, unknown, unknown, unknown}
+	for i, action := range actions {
+		trace := action.(*ActionTrace)
+		assert.Equal(t, expectedDepths[i], trace.Depth(), "action %d", i)
+		assert.Equal(t, uint32(0), trace.Parent(), "action %d", i)
+	}
+
+	counts := &core.InlineCounts{}
+	for _, action := range actions {
+		counts.Add(action)
+	}
+	assert.Equal(t, uint64(2), counts.TopLevelCount)
+	assert.Equal(t, uint64(0), counts.InlineCount)
+	assert.Equal(t, uint64(6), counts.NotificationsCount)
+	assert.Equal(t, uint64(1), counts.UnknownDepthCount)
+
+	var transfers []core.Transfer
+	for _, action := range actions {
+		if transfer, ok := core.GetTransfer(action); ok {
+			transfers = append(transfers, transfer)
+		}
+	}
+	assert.Len(t, transfers, 3)
+	assert.Equal(t, "dice", transfers[2].From)
+	assert.Equal(t, "0.98 EOS@eosio.token", transfers[2].Amount.String())
+}
+
+func TestTraceListTransactions(t *testing.T) {
+	transactions := readTraceBlocks(t)[0].ListTransactions()
+	assert.Len(t, transactions, 2)
+	assert.Equal(t, "t1", transactions[0].ID)
+	assert.Equal(t, "alice", transactions[0].Signer)
+	assert.Len(t, transactions[0].Actions, 7)
+	assert.Equal(t, 1, transactions[1].Index)
+	assert.Equal(t, "carol", transactions[1].Signer)
+}
+
+func TestComputeInlineActionsOverTime(t *testing.T) {
+	inlineActions, err := processor.ComputeInlineActionsOverTime(
+		newTracesEOS(), core.GetFixture(core.EOSTracesFilename),
+		0, 0, core.TimeRange{}, nil, core.NewDuration(time.Hour))
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(9), inlineActions.Total.ActionsCount)
+	assert.Equal(t, uint64(3), inlineActions.Total.TopLevelCount)
+	assert.Equal(t, uint64(2), inlineActions.Total.InlineCount)
+	assert.Equal(t, uint64(4), inlineActions.Total.NotificationsCount)
+	assert.Equal(t, 2, inlineActions.Total.MaxDepth)
+	assert.InDelta(t, 0.4, inlineActions.Total.InlineRatio, 1e-9)
+	assert.Len(t, inlineActions.OverTime, 2)
+}
@@ -3,7 +3,6 @@ package eos
 import (
 	"encoding/csv"
 	"fmt"
-	"log"
 	"strconv"
 	"strings"
 
@@ -18,6 +17,18 @@ type TransferData struct {
 	Memo     string
 }
 
+// LegacyTransferColumns are the columns written by ExportTransfers
+var LegacyTransferColumns = []string{
+	"block",
+	"tx",
+	"account",
+	"symbol",
+	"from",
+	"to",
+	"quantity",
+	"memo",
+}
+
 func parseTransferQuantity(rawQuantity string) (string, string, error) {
 	tokens := strings.Split(rawQuantity, " ")
 	if len(tokens) != 2 {
@@ -29,67 +40,52 @@ func parseTransferQuantity(rawQuantity string) (string, string, error) {
 	return tokens[0], tokens[1], nil
 }
 
-func ExportTransfers(globPattern string, start, end uint64, output string) error {
+// ExportTransfers writes the transfers to a CSV file with LegacyTransferColumns,
+// where quantities keep the precision of the token
+//
+// Deprecated: use processor.ExportTransfers, which writes the columns shared by all blockchains
+func ExportTransfers(globPattern string, start, end uint64, timeRange core.TimeRange, output string) error {
 	writer, err := core.CreateFile(output)
 	if err != nil {
 		return err
 	}
 	defer writer.Close()
 	csvWriter := csv.NewWriter(writer)
-
-	headers := []string{
-		"block",
-		"tx",
-		"account",
-		"symbol",
-		"from",
-		"to",
-		"quantity",
-		"memo",
-	}
-	if err := csvWriter.Write(headers); err != nil {
+	if err := csvWriter.Write(LegacyTransferColumns); err != nil {
 		return err
 	}
 
-	blocks, err := processor.YieldAllBlocks(globPattern, New(), start, end)
+	blocks, err := processor.YieldAllBlocksInRange(globPattern, New(), start, end, timeRange)
 	if err != nil {
 		return err
 	}
 	for block := range blocks {
-		eosBlock, ok := block.(*Block)
-		if !ok {
-			return err
-		}
-
-		for _, transaction := range eosBlock.Transactions {
-			for _, action := range transaction.Trx.Transaction.Actions {
-				if action.ActionName != "transfer" {
-					continue
-				}
-				var transferData TransferData
-				if err := fastJson.Unmarshal(action.Data, &transferData); err != nil {
+		for _, transaction := range block.ListTransactions() {
+			for _, action := range transaction.Actions {
+				transfer, ok := core.GetTransfer(action)
+				if !ok {
 					continue
 				}
-				quantity, symbol, err := parseTransferQuantity(transferData.Quantity)
+				quantity, symbol, err := parseTransferQuantity(core.GetDataField(action, "quantity"))
 				if err != nil {
 					continue
 				}
-
 				row := []string{
 					strconv.FormatUint(block.Number(), 10),
-					transaction.Trx.Id,
-					action.Account,
+					transaction.ID,
+					transfer.Amount.Issuer,
 					symbol,
-					transferData.From,
-					transferData.To,
+					transfer.From,
+					transfer.To,
 					quantity,
-					transferData.Memo,
+					core.GetDataField(action, "memo"),
 				}
-				if err = csvWriter.Write(row); err != nil {
-					log.Printf("could not write row: %s", err.Error())
+				if err := csvWriter.Write(row); err != nil {
+					return err
 				}
 			}
 		}
 	}
-	return nil
+	csvWriter.Flush()
+	return csvWriter.Error()
 }
@@ -0,0 +1,29 @@
+package eos
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/danhper/blockchain-analyzer/core"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportTransfers(t *testing.T) {
+	outputDir, err := ioutil.TempDir("", "transfers")
+	assert.Nil(t, err)
+	defer os.RemoveAll(outputDir)
+	output := path.Join(outputDir, "transfers.csv")
+
+	filepath := core.GetFixture(core.EOSValidBlocksFilename)
+	assert.Nil(t, ExportTransfers(filepath, uint64(0), uint64(0), core.TimeRange{}, output))
+	exported, err := ioutil.ReadFile(output)
+	assert.Nil(t, err)
+	lines := strings.Split(strings.TrimSpace(string(exported)), "\n")
+	assert.Equal(t, strings.Join(LegacyTransferColumns, ","), lines[0])
+	assert.Len(t, lines, 43133)
+	assert.Contains(t, lines, "120893628,ab85b06509f039d111441e4cc6e2eb0582d73dcc78c55d295cff8d8f213f82a8,"+
+		"eosio.token,EOS,iamdifferent,eidosonecoin,0.0001,a1028501")
+}
@@ -2,6 +2,7 @@ package fetcher
 
 import (
 	"bytes"
+	"fmt"
 	"io/ioutil"
 	"log"
 	"net/http"
@@ -17,11 +18,16 @@ func fetchBlockWithRetry(
 	blockNumber uint64, retries int,
 ) (result []byte, err error) {
 	resp, err := context.MakeRequest(client, blockNumber)
-	if err == nil && resp.StatusCode == 200 {
-		result, err = ioutil.ReadAll(resp.Body)
+	if err == nil {
+		defer resp.Body.Close()
+		if resp.StatusCode == 200 {
+			result, err = ioutil.ReadAll(resp.Body)
+		} else {
+			err = fmt.Errorf("unexpected status %d", resp.StatusCode)
+		}
 	}
-	if (err != nil || resp.StatusCode != 200) && retries > 0 {
-		log.Printf("error: %s (status %d), retrying", err.Error(), resp.StatusCode)
+	if err != nil && retries > 0 {
+		log.Printf("error: %s, retrying", err.Error())
 		time.Sleep(time.Second)
 		return fetchBlockWithRetry(client, context, blockNumber, retries-1)
 	}
@@ -32,6 +38,13 @@ func fetchBlock(blockNumber uint64, client *http.Client, context *HTTPContext) (
 	return fetchBlockWithRetry(client, context, blockNumber, 3)
 }
 
+// FetchHTTPBlock fetches a single block, e.g. to implement core.BlockFetcher
+func FetchHTTPBlock(blockNumber uint64, makeRequest RequestSender) ([]byte, error) {
+	client := &http.Client{Timeout: 30 * time.Second}
+	context := NewHTTPContext(blockNumber, blockNumber, makeRequest)
+	return fetchBlock(blockNumber, client, context)
+}
+
 type HTTPContext struct {
 	DoneCount   uint64
 	Start       uint64
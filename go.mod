@@ -11,4 +11,5 @@ require (
 	github.com/stretchr/testify v1.5.1
 	github.com/ugorji/go/codec v1.1.7
 	github.com/urfave/cli/v2 v2.2.0
+	github.com/xitongsys/parquet-go v1.5.1
 )
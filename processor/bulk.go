@@ -12,9 +12,16 @@ type Aggregator interface {
 	Result() interface{}
 }
 
+// Finalizer is implemented by aggregators which need to run
+// once all the blocks have been processed, e.g. to export data
+type Finalizer interface {
+	Finalize() error
+}
+
 type Processor struct {
 	Aggregator Aggregator
 	Name       string
+	Filter     *core.Filter
 }
 
 func NewProcessor(name string, aggregator Aggregator) Processor {
@@ -24,30 +31,137 @@ func NewProcessor(name string, aggregator Aggregator) Processor {
 	}
 }
 
+func NewFilteredProcessor(name string, aggregator Aggregator, filter *core.Filter) Processor {
+	processor := NewProcessor(name, aggregator)
+	processor.Filter = filter
+	return processor
+}
+
+// AddBlock passes the block to the aggregator, only keeping
+// the actions matching the filter of the processor if any
+func (p Processor) AddBlock(block core.Block) {
+	p.Aggregator.AddBlock(p.Filter.Apply(block))
+}
+
+// resultsLimitsParams are the number of results to keep in the output,
+// 0 meaning unlimited and nil the default limits
+type resultsLimitsParams struct {
+	Top       *int
+	NestedTop *int
+}
+
+func (p resultsLimitsParams) limits() (top int, nested int) {
+	top, nested = core.DefaultTopLevelResults, core.DefaultNestedResults
+	if p.Top != nil {
+		top = *p.Top
+	}
+	if p.NestedTop != nil {
+		nested = *p.NestedTop
+	}
+	return
+}
+
 type groupActionsParams struct {
-	By       core.ActionProperty
+	resultsLimitsParams
+	By       core.ActionProperties
 	Detailed bool
 }
 
 type groupActionsOverTimeParams struct {
-	By       core.ActionProperty
+	resultsLimitsParams
+	By       core.ActionProperties
+	Duration core.Duration
+}
+
+type newAccountsOverTimeParams struct {
 	Duration core.Duration
+	Export   string
+}
+
+type approxUniqueParams struct {
+	By            core.ActionProperties
+	RelativeError float64
+}
+
+type heavyHittersParams struct {
+	By      core.ActionProperties
+	K       int
+	Epsilon float64
+	Delta   float64
 }
 
-type countTransactionsOverTimeParams struct {
+type concentrationParams struct {
+	Duration core.Duration
+	By       core.ActionProperties
+	TopN     []int
+}
+
+type producersParams struct {
+	Duration core.Duration
+	TopN     []int
+}
+
+type blockTimesParams struct {
+	Duration    core.Duration
+	StallFactor float64
+}
+
+type transferVolumeParams struct {
+	Duration    core.Duration
+	TopAccounts *int
+}
+
+type actionsPerTransactionParams struct {
+	TopPatterns *int
+}
+
+type feesParams struct {
+	Duration   core.Duration
+	TopSenders *int
+}
+
+type durationParams struct {
 	Duration core.Duration
 }
 
 type BulkConfig struct {
-	Pattern       string
-	StartBlock    uint64
-	EndBlock      uint64
+	Pattern    string
+	StartBlock uint64
+	EndBlock   uint64
+	StartTime  core.Timestamp
+	EndTime    core.Timestamp
+	// Categories are the files of the categories used by category properties
+	Categories []string `json:",omitempty"`
+	// Blockchain are the options of the blockchain the data was processed with
+	Blockchain    interface{} `json:",omitempty"`
 	RawProcessors []struct {
 		Name   string
 		Type   string
+		Filter *core.Filter `json:",omitempty"`
 		Params json.RawMessage
 	} `json:"Processors"`
 	Processors []Processor `json:"-"`
+	categories *core.Categories
+}
+
+// NewBulkConfig returns a configuration to unmarshal, where categories are used
+// by the category properties unless the configuration gives its own Categories
+func NewBulkConfig(categories *core.Categories) *BulkConfig {
+	return &BulkConfig{categories: categories}
+}
+
+// requireDuration returns an error for processors bucketing by time without a duration
+func requireDuration(name string, duration core.Duration) error {
+	if duration.IsZero() {
+		return fmt.Errorf("processor %s requires a duration", name)
+	}
+	return nil
+}
+
+// setCategories sets the categories used by the category properties of properties
+func (c *BulkConfig) setCategories(properties *core.ActionProperties) (err error) {
+	*properties, err = properties.WithCategories(c.categories)
+	return err
 }
 
 func (c *BulkConfig) UnmarshalJSON(data []byte) error {
@@ -55,6 +169,13 @@ func (c *BulkConfig) UnmarshalJSON(data []byte) error {
 	if err := json.Unmarshal(data, (*rawConfig)(c)); err != nil {
 		return err
 	}
+	if len(c.Categories) > 0 {
+		categories, err := core.LoadCategories(c.Categories...)
+		if err != nil {
+			return err
+		}
+		c.categories = categories
+	}
 	for _, rawProcessor := range c.RawProcessors {
 		var aggregator Aggregator
 		switch rawProcessor.Type {
@@ -63,48 +184,235 @@ func (c *BulkConfig) UnmarshalJSON(data []byte) error {
 			if err := json.Unmarshal(rawProcessor.Params, &params); err != nil {
 				return err
 			}
-			aggregator = core.NewGroupedActions(params.By, params.Detailed)
+			if err := c.setCategories(&params.By); err != nil {
+				return err
+			}
+			aggregator = core.NewGroupedActions(params.By, params.Detailed).
+				SetResultsLimits(params.limits())
 
 		case "count-transactions":
 			aggregator = core.NewTransactionCounter()
 
 		case "count-transactions-over-time":
-			var params countTransactionsOverTimeParams
+			var params durationParams
 			if err := json.Unmarshal(rawProcessor.Params, &params); err != nil {
 				return err
 			}
-			aggregator = core.NewTimeGroupedTransactionCount(params.Duration.Duration)
+			if err := requireDuration(rawProcessor.Name, params.Duration); err != nil {
+				return err
+			}
+			aggregator = core.NewTimeGroupedTransactionCount(params.Duration)
 
 		case "group-actions-over-time":
 			var params groupActionsOverTimeParams
 			if err := json.Unmarshal(rawProcessor.Params, &params); err != nil {
 				return err
 			}
-			aggregator = core.NewTimeGroupedActions(params.Duration.Duration, params.By)
+			if err := c.setCategories(&params.By); err != nil {
+				return err
+			}
+			if err := requireDuration(rawProcessor.Name, params.Duration); err != nil {
+				return err
+			}
+			aggregator = core.NewTimeGroupedActions(params.Duration, params.By).
+				SetResultsLimits(params.limits())
+
+		case "active-accounts-over-time":
+			var params durationParams
+			if err := json.Unmarshal(rawProcessor.Params, &params); err != nil {
+				return err
+			}
+			if err := requireDuration(rawProcessor.Name, params.Duration); err != nil {
+				return err
+			}
+			aggregator = core.NewTimeGroupedActiveAccounts(params.Duration)
+
+		case "new-accounts-over-time":
+			var params newAccountsOverTimeParams
+			if err := json.Unmarshal(rawProcessor.Params, &params); err != nil {
+				return err
+			}
+			if err := requireDuration(rawProcessor.Name, params.Duration); err != nil {
+				return err
+			}
+			aggregator = core.NewFirstSeenAccounts(params.Duration, params.Export)
+
+		case "retention":
+			var params durationParams
+			if err := json.Unmarshal(rawProcessor.Params, &params); err != nil {
+				return err
+			}
+			if err := requireDuration(rawProcessor.Name, params.Duration); err != nil {
+				return err
+			}
+			aggregator = core.NewRetention(params.Duration)
+
+		case "approx-unique":
+			params := approxUniqueParams{RelativeError: 0.01}
+			if err := json.Unmarshal(rawProcessor.Params, &params); err != nil {
+				return err
+			}
+			if err := c.setCategories(&params.By); err != nil {
+				return err
+			}
+			var err error
+			if aggregator, err = core.NewApproxUniqueActions(params.By, params.RelativeError); err != nil {
+				return err
+			}
+
+		case "heavy-hitters":
+			params := heavyHittersParams{K: 100, Epsilon: 0.0001, Delta: 0.01}
+			if err := json.Unmarshal(rawProcessor.Params, &params); err != nil {
+				return err
+			}
+			if err := c.setCategories(&params.By); err != nil {
+				return err
+			}
+			var err error
+			aggregator, err = core.NewHeavyHitters(params.By, params.K, params.Epsilon, params.Delta)
+			if err != nil {
+				return err
+			}
+
+		case "concentration":
+			params := concentrationParams{
+				By:   core.DefaultConcentrationProperties,
+				TopN: core.DefaultConcentrationTopN,
+			}
+			if err := json.Unmarshal(rawProcessor.Params, &params); err != nil {
+				return err
+			}
+			if err := c.setCategories(&params.By); err != nil {
+				return err
+			}
+			if err := requireDuration(rawProcessor.Name, params.Duration); err != nil {
+				return err
+			}
+			aggregator = core.NewTimeGroupedConcentration(params.Duration, params.By, params.TopN)
+
+		case "producers":
+			params := producersParams{TopN: core.DefaultConcentrationTopN}
+			if err := json.Unmarshal(rawProcessor.Params, &params); err != nil {
+				return err
+			}
+			if err := requireDuration(rawProcessor.Name, params.Duration); err != nil {
+				return err
+			}
+			aggregator = core.NewProducersOverTime(params.Duration, params.TopN)
+
+		case "block-times":
+			params := blockTimesParams{StallFactor: core.DefaultStallFactor}
+			if err := json.Unmarshal(rawProcessor.Params, &params); err != nil {
+				return err
+			}
+			if err := requireDuration(rawProcessor.Name, params.Duration); err != nil {
+				return err
+			}
+			aggregator = core.NewBlockTimes(params.Duration, params.StallFactor)
+
+		case "distribution":
+			var params durationParams
+			if err := json.Unmarshal(rawProcessor.Params, &params); err != nil {
+				return err
+			}
+			// the duration is optional, only the overall distributions are computed without it
+			aggregator = core.NewDistribution(params.Duration)
+
+		case "transfer-volume":
+			var params transferVolumeParams
+			if err := json.Unmarshal(rawProcessor.Params, &params); err != nil {
+				return err
+			}
+			transferVolume := core.NewTransferVolume(params.Duration)
+			if params.TopAccounts != nil {
+				transferVolume.SetAccountsLimit(*params.TopAccounts)
+			}
+			aggregator = transferVolume
+
+		case "failure-rate-over-time":
+			var params durationParams
+			if err := json.Unmarshal(rawProcessor.Params, &params); err != nil {
+				return err
+			}
+			if err := requireDuration(rawProcessor.Name, params.Duration); err != nil {
+				return err
+			}
+			aggregator = core.NewFailureRateOverTime(params.Duration)
+
+		case "inline-actions-over-time":
+			var params durationParams
+			if err := json.Unmarshal(rawProcessor.Params, &params); err != nil {
+				return err
+			}
+			if err := requireDuration(rawProcessor.Name, params.Duration); err != nil {
+				return err
+			}
+			aggregator = core.NewInlineActionsOverTime(params.Duration)
+
+		case "fees-over-time":
+			var params feesParams
+			if err := json.Unmarshal(rawProcessor.Params, &params); err != nil {
+				return err
+			}
+			if err := requireDuration(rawProcessor.Name, params.Duration); err != nil {
+				return err
+			}
+			fees := core.NewFeesOverTime(params.Duration)
+			if params.TopSenders != nil {
+				fees.SetTopSenders(*params.TopSenders)
+			}
+			aggregator = fees
+
+		case "actions-per-transaction":
+			var params actionsPerTransactionParams
+			if err := json.Unmarshal(rawProcessor.Params, &params); err != nil {
+				return err
+			}
+			actionsPerTransaction := core.NewActionsPerTransaction()
+			if params.TopPatterns != nil {
+				actionsPerTransaction.SetPatternsLimit(*params.TopPatterns)
+			}
+			aggregator = actionsPerTransaction
 
 		default:
 			return fmt.Errorf("unknown processor %s", rawProcessor.Name)
 		}
-		processor := NewProcessor(rawProcessor.Name, aggregator)
+		processor := NewFilteredProcessor(rawProcessor.Name, aggregator, rawProcessor.Filter)
 		c.Processors = append(c.Processors, processor)
 	}
 	return nil
 }
 
+func (c *BulkConfig) TimeRange() core.TimeRange {
+	return core.NewTimeRange(c.StartTime.Time, c.EndTime.Time)
+}
+
 func RunBulkActions(blockchain core.Blockchain, config BulkConfig) (map[string]interface{}, error) {
 	missingBlockProcessor := NewProcessor("MissingBlocks", core.NewMissingBlocks(config.StartBlock, config.EndBlock))
 	config.Processors = append(config.Processors, missingBlockProcessor)
-	blocks, err := YieldAllBlocks(config.Pattern, blockchain, config.StartBlock, config.EndBlock)
+	blocks, err := YieldAllBlocksInRange(
+		config.Pattern, blockchain, config.StartBlock, config.EndBlock, config.TimeRange())
 	if err != nil {
 		return nil, err
 	}
 
 	for block := range blocks {
 		for _, processor := range config.Processors {
-			processor.Aggregator.AddBlock(block)
+			processor.AddBlock(block)
 		}
 	}
 
+	for _, processor := range config.Processors {
+		if finalizer, ok := processor.Aggregator.(Finalizer); ok {
+			if err := finalizer.Finalize(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if configurable, ok := blockchain.(core.ConfigurableBlockchain); ok {
+		config.Blockchain = configurable.Options()
+	}
 	result := make(map[string]interface{})
 	result["Config"] = config
 	processorResults := make(map[string]interface{})
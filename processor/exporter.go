@@ -15,12 +15,14 @@ func ExportToMsgpack(
 	blockchain core.Blockchain,
 	globPattern string,
 	start, end uint64,
+	timeRange core.TimeRange,
 	outputDir string,
 ) error {
 	files, err := filepath.Glob(globPattern)
 	if err != nil {
 		return err
 	}
+	files = FilterFilesByTime(files, timeRange)
 	processed := 0
 	fileDone := make(chan bool)
 	var wg sync.WaitGroup
@@ -43,7 +45,8 @@ func ExportToMsgpack(
 
 		for block := range YieldBlocks(reader, blockchain, JSONFormat) {
 			if (start == 0 || block.Number() >= start) &&
-				(end == 0 || block.Number() <= end) {
+				(end == 0 || block.Number() <= end) &&
+				timeRange.Contains(block.Time()) {
 				var rawBlock []byte
 				enc := codec.NewEncoderBytes(&rawBlock, msgpackHandle)
 				if err := enc.Encode(block); err != nil {
@@ -0,0 +1,185 @@
+package processor
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/danhper/blockchain-analyzer/core"
+	"github.com/ugorji/go/codec"
+)
+
+type partitionWriters struct {
+	outputPath string
+	partition  core.TimePartition
+	writers    map[string]io.WriteCloser
+	seen       map[uint64]bool
+	mutex      sync.Mutex
+}
+
+func newPartitionWriters(outputPath string, partition core.TimePartition) *partitionWriters {
+	return &partitionWriters{
+		outputPath: outputPath,
+		partition:  partition,
+		writers:    make(map[string]io.WriteCloser),
+		seen:       make(map[uint64]bool),
+	}
+}
+
+func (p *partitionWriters) write(block core.Block, rawBlock []byte) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.seen[block.Number()] {
+		return nil
+	}
+	p.seen[block.Number()] = true
+
+	filename := core.MakeTimePartitionFilename(p.outputPath, block.Time(), p.partition)
+	writer, ok := p.writers[filename]
+	if !ok {
+		var err error
+		if writer, err = core.CreateFile(filename); err != nil {
+			return err
+		}
+		p.writers[filename] = writer
+	}
+	_, err := writer.Write(rawBlock)
+	return err
+}
+
+// close closes all the files, which also flushes gzip files, and returns the errors
+func (p *partitionWriters) close() []error {
+	var errs []error
+	for filename, writer := range p.writers {
+		if err := writer.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("could not close %s: %s", filename, err.Error()))
+		}
+	}
+	return errs
+}
+
+// partitionErrors collects the errors of the files processed concurrently
+type partitionErrors struct {
+	errs  []error
+	mutex sync.Mutex
+}
+
+func (p *partitionErrors) add(err error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.errs = append(p.errs, err)
+}
+
+func (p *partitionErrors) err() error {
+	if len(p.errs) == 0 {
+		return nil
+	}
+	messages := make([]string, len(p.errs))
+	for i, err := range p.errs {
+		messages[i] = err.Error()
+	}
+	sort.Strings(messages)
+	return fmt.Errorf("%d errors while partitioning: %s", len(messages), strings.Join(messages, "; "))
+}
+
+// PartitionByTime rewrites the blocks matching the pattern into one file
+// per day or month, named after outputPath, e.g. eos-2020-03.jsonl.gz
+// The output format must be the same as the format of the input files
+// Files failing to be read or written do not stop the other files and
+// all the errors, including these of closing the output files, are returned
+func PartitionByTime(
+	blockchain core.Blockchain,
+	globPattern string,
+	start, end uint64,
+	timeRange core.TimeRange,
+	partition core.TimePartition,
+	outputPath string,
+) error {
+	outputFormat, err := InferFormat(outputPath)
+	if err != nil {
+		return err
+	}
+	files, err := filepath.Glob(globPattern)
+	if err != nil {
+		return err
+	}
+	files = FilterFilesByTime(files, timeRange)
+
+	writers := newPartitionWriters(outputPath, partition)
+	errs := &partitionErrors{}
+
+	processed := 0
+	fileDone := make(chan bool)
+	var wg sync.WaitGroup
+
+	partitionFile := core.MakeFileProcessor(func(filename string) (err error) {
+		defer wg.Done()
+		defer func() {
+			fileDone <- true
+			if err != nil {
+				errs.add(fmt.Errorf("%s: %s", filename, err.Error()))
+			}
+		}()
+		fileFormat, err := InferFormat(filename)
+		if err != nil {
+			return err
+		}
+		if fileFormat != outputFormat {
+			return fmt.Errorf("%s and %s do not have the same format", filename, outputPath)
+		}
+		reader, err := core.OpenFile(filename)
+		if err != nil {
+			return err
+		}
+		defer reader.Close()
+
+		readErr := readBlocks(reader, blockchain, fileFormat, func(block core.Block, rawBlock []byte) {
+			if err != nil {
+				return
+			}
+			if (start != 0 && block.Number() < start) ||
+				(end != 0 && block.Number() > end) ||
+				!timeRange.Contains(block.Time()) {
+				return
+			}
+			if fileFormat == MsgpackFormat {
+				enc := codec.NewEncoderBytes(&rawBlock, msgpackHandle)
+				if err = enc.Encode(block); err != nil {
+					return
+				}
+			}
+			err = writers.write(block, rawBlock)
+		})
+		if err == nil {
+			err = readErr
+		}
+		return err
+	})
+
+	go func() {
+		for range fileDone {
+			processed++
+			log.Printf("files processed: %d/%d", processed, len(files))
+		}
+	}()
+
+	log.Printf("partitioning %d files by %s", len(files), partition)
+
+	for _, filename := range files {
+		wg.Add(1)
+		go partitionFile(filename)
+	}
+
+	wg.Wait()
+	close(fileDone)
+
+	for _, err := range writers.close() {
+		errs.add(err)
+	}
+	return errs.err()
+}
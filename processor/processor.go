@@ -6,11 +6,11 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"math/big"
 	"os"
 	"path/filepath"
 	"strings"
 	"sync"
-	"time"
 
 	"github.com/danhper/blockchain-analyzer/core"
 	"github.com/ugorji/go/codec"
@@ -39,64 +39,97 @@ func InferFormat(filepath string) (FileFormat, error) {
 	return JSONFormat, fmt.Errorf("invalid filename %s", filepath)
 }
 
-func YieldBlocks(reader io.Reader, blockchain core.Blockchain, format FileFormat) <-chan core.Block {
+// readBlocks calls f with each block of the reader and returns
+// the error interrupting the read, if any, blocks failing to parse being skipped
+func readBlocks(reader io.Reader, blockchain core.Blockchain, format FileFormat,
+	f func(block core.Block, rawBlock []byte)) error {
 	stream := bufio.NewReader(reader)
-	blocks := make(chan core.Block)
 
 	var decoder *codec.Decoder
 	if format == MsgpackFormat {
 		decoder = codec.NewDecoder(stream, msgpackHandle)
 	}
 
-	go func() {
-		defer close(blocks)
-
-		for i := 0; ; i++ {
-			if i%logInterval == 0 {
-				log.Printf("processed: %d", i)
+	for i := 0; ; i++ {
+		if i%logInterval == 0 {
+			log.Printf("processed: %d", i)
+		}
+		block := blockchain.EmptyBlock()
+		var rawLine []byte
+		var err error
+		switch format {
+		case JSONFormat:
+			line, err := stream.ReadBytes('\n')
+			if err == io.EOF {
+				return nil
 			}
-			block := blockchain.EmptyBlock()
-			var err error
-			switch format {
-			case JSONFormat:
-				rawLine, err := stream.ReadBytes('\n')
-				if err == io.EOF {
-					return
-				}
-				if err != nil {
-					log.Printf("failed to read line %s\n", err.Error())
-					return
-				}
-				rawLine = bytes.ToValidUTF8(rawLine, []byte{})
-				block, err = blockchain.ParseBlock(rawLine)
-			case MsgpackFormat:
-				err = decoder.Decode(&block)
+			if err != nil {
+				log.Printf("failed to read line %s\n", err.Error())
+				return err
 			}
+			rawLine = bytes.ToValidUTF8(line, []byte{})
+			block, err = blockchain.ParseBlock(rawLine)
+		case MsgpackFormat:
+			err = decoder.Decode(&block)
+		}
 
-			if err == io.EOF {
-				break
-			} else if err != nil {
-				log.Printf("could not parse: %s", err.Error())
-				continue
-			}
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			log.Printf("could not parse: %s", err.Error())
+			continue
+		}
 
-			if block != nil {
-				blocks <- block
-			}
+		if block != nil {
+			f(block, rawLine)
 		}
-	}()
+	}
+	return nil
+}
 
+func YieldBlocks(reader io.Reader, blockchain core.Blockchain, format FileFormat) <-chan core.Block {
+	blocks := make(chan core.Block)
+	go func() {
+		defer close(blocks)
+		readBlocks(reader, blockchain, format, func(block core.Block, _ []byte) {
+			blocks <- block
+		})
+	}()
 	return blocks
 }
 
+// FilterFilesByTime removes the time-partitioned files which are outside of the given range
+func FilterFilesByTime(files []string, timeRange core.TimeRange) []string {
+	if timeRange.IsZero() {
+		return files
+	}
+	var result []string
+	for _, filename := range files {
+		from, to, ok := core.ParseTimePartitionFilename(filename)
+		if !ok || timeRange.Overlaps(from, to) {
+			result = append(result, filename)
+		}
+	}
+	return result
+}
+
 func YieldAllBlocks(
 	globPattern string,
 	blockchain core.Blockchain,
 	start, end uint64) (<-chan core.Block, error) {
+	return YieldAllBlocksInRange(globPattern, blockchain, start, end, core.TimeRange{})
+}
+
+func YieldAllBlocksInRange(
+	globPattern string,
+	blockchain core.Blockchain,
+	start, end uint64,
+	timeRange core.TimeRange) (<-chan core.Block, error) {
 	files, err := filepath.Glob(globPattern)
 	if err != nil {
 		return nil, err
 	}
+	files = FilterFilesByTime(files, timeRange)
 
 	log.Printf("starting for %d files", len(files))
 	blocks := make(chan core.Block)
@@ -119,7 +152,8 @@ func YieldAllBlocks(
 		defer reader.Close()
 		for block := range YieldBlocks(reader, blockchain, fileFormat) {
 			if (start == 0 || block.Number() >= start) &&
-				(end == 0 || block.Number() <= end) {
+				(end == 0 || block.Number() <= end) &&
+				timeRange.Contains(block.Time()) {
 				blocks <- block
 			}
 		}
@@ -201,59 +235,223 @@ func OutputAllMissingBlockNumbers(
 	return nil
 }
 
-func CountTransactions(blockchain core.Blockchain, globPattern string, start, end uint64) (int, error) {
-	blocks, err := YieldAllBlocks(globPattern, blockchain, start, end)
+// AggregateBlocks passes the blocks in range to the aggregator, only keeping the
+// actions matching the filter, and finalizes the aggregator if needed
+func AggregateBlocks(blockchain core.Blockchain, globPattern string,
+	start, end uint64, timeRange core.TimeRange, filter *core.Filter,
+	aggregator Aggregator) error {
+	blocks, err := YieldAllBlocksInRange(globPattern, blockchain, start, end, timeRange)
 	if err != nil {
-		return 0, err
+		return err
 	}
-	txCounter := core.NewTransactionCounter()
+	processor := NewFilteredProcessor("", aggregator, filter)
 	for block := range blocks {
-		txCounter.AddBlock(block)
+		processor.AddBlock(block)
+	}
+	if finalizer, ok := aggregator.(Finalizer); ok {
+		return finalizer.Finalize()
 	}
-	return (int)(*txCounter), nil
+	return nil
+}
+
+func CountTransactions(blockchain core.Blockchain, globPattern string,
+	start, end uint64, timeRange core.TimeRange, filter *core.Filter) (int, error) {
+	txCounter := core.NewTransactionCounter()
+	err := AggregateBlocks(blockchain, globPattern, start, end, timeRange, filter, txCounter)
+	return (int)(*txCounter), err
 }
 
 func CountActionsOverTime(
 	blockchain core.Blockchain,
 	globPattern string,
 	start, end uint64,
-	duration time.Duration,
-	actionProperty core.ActionProperty) (*core.TimeGroupedActions, error) {
-	blocks, err := YieldAllBlocks(globPattern, blockchain, start, end)
-	if err != nil {
-		return nil, err
-	}
-	result := core.NewTimeGroupedActions(duration, actionProperty)
-	for block := range blocks {
-		result.AddBlock(block)
-	}
-	return result, nil
+	timeRange core.TimeRange,
+	filter *core.Filter,
+	duration core.Duration,
+	actionProperties core.ActionProperties) (*core.TimeGroupedActions, error) {
+	result := core.NewTimeGroupedActions(duration, actionProperties)
+	err := AggregateBlocks(blockchain, globPattern, start, end, timeRange, filter, result)
+	return result, err
 }
 
 func CountTransactionsOverTime(blockchain core.Blockchain, globPattern string,
-	start, end uint64, duration time.Duration,
+	start, end uint64, timeRange core.TimeRange, filter *core.Filter, duration core.Duration,
 ) (*core.TimeGroupedTransactionCount, error) {
-	blocks, err := YieldAllBlocks(globPattern, blockchain, start, end)
-	if err != nil {
-		return nil, err
-	}
 	result := core.NewTimeGroupedTransactionCount(duration)
-	for block := range blocks {
-		result.AddBlock(block)
-	}
-	return result, nil
+	err := AggregateBlocks(blockchain, globPattern, start, end, timeRange, filter, result)
+	return result, err
 }
 
 func GroupActions(blockchain core.Blockchain, globPattern string,
-	start, end uint64, by core.ActionProperty, detailed bool,
+	start, end uint64, timeRange core.TimeRange, filter *core.Filter,
+	by core.ActionProperties, detailed bool,
 ) (*core.GroupedActions, error) {
-	blocks, err := YieldAllBlocks(globPattern, blockchain, start, end)
+	groupedActions := core.NewGroupedActions(by, detailed)
+	err := AggregateBlocks(blockchain, globPattern, start, end, timeRange, filter, groupedActions)
+	return groupedActions, err
+}
+
+func CountActiveAccountsOverTime(blockchain core.Blockchain, globPattern string,
+	start, end uint64, timeRange core.TimeRange, filter *core.Filter, duration core.Duration,
+) (*core.TimeGroupedActiveAccounts, error) {
+	result := core.NewTimeGroupedActiveAccounts(duration)
+	err := AggregateBlocks(blockchain, globPattern, start, end, timeRange, filter, result)
+	return result, err
+}
+
+func CountNewAccountsOverTime(blockchain core.Blockchain, globPattern string,
+	start, end uint64, timeRange core.TimeRange, filter *core.Filter,
+	duration core.Duration, exportPath string,
+) (*core.FirstSeenAccounts, error) {
+	result := core.NewFirstSeenAccounts(duration, exportPath)
+	err := AggregateBlocks(blockchain, globPattern, start, end, timeRange, filter, result)
+	return result, err
+}
+
+func ComputeRetention(blockchain core.Blockchain, globPattern string,
+	start, end uint64, timeRange core.TimeRange, filter *core.Filter, duration core.Duration,
+) (*core.Retention, error) {
+	result := core.NewRetention(duration)
+	err := AggregateBlocks(blockchain, globPattern, start, end, timeRange, filter, result)
+	return result, err
+}
+
+// ExportGraph writes the sender -> receiver graph of the actions to output
+// keeping only the edges with at least minWeight actions
+func ExportGraph(blockchain core.Blockchain, globPattern string,
+	start, end uint64, timeRange core.TimeRange, filter *core.Filter,
+	byName bool, minWeight uint64, format core.GraphFormat, output string,
+) error {
+	graph := core.NewInteractionGraph(byName)
+	if err := AggregateBlocks(blockchain, globPattern, start, end, timeRange, filter, graph); err != nil {
+		return err
+	}
+	writer, err := core.CreateFile(output)
+	if err != nil {
+		return err
+	}
+	defer writer.Close()
+	return graph.Write(writer, format, minWeight)
+}
+
+// ExportTransfers writes the transfers of the matching actions to output
+func ExportTransfers(blockchain core.Blockchain, globPattern string,
+	start, end uint64, timeRange core.TimeRange, filter *core.Filter,
+	format core.TransferFormat, output string,
+) error {
+	writer, err := core.CreateFile(output)
+	if err != nil {
+		return err
+	}
+	defer writer.Close()
+	transferWriter, err := core.NewTransferWriter(writer, format)
+	if err != nil {
+		return err
+	}
+	exporter := core.NewTransferExporter(blockchain, transferWriter)
+	return AggregateBlocks(blockchain, globPattern, start, end, timeRange, filter, exporter)
+}
+
+func ComputeGraphStats(blockchain core.Blockchain, globPattern string,
+	start, end uint64, timeRange core.TimeRange, filter *core.Filter, top int,
+) (*core.GraphStats, error) {
+	graph := core.NewInteractionGraph(false)
+	err := AggregateBlocks(blockchain, globPattern, start, end, timeRange, filter, graph)
 	if err != nil {
 		return nil, err
 	}
-	groupedActions := core.NewGroupedActions(by, detailed)
-	for block := range blocks {
-		groupedActions.AddBlock(block)
+	return graph.ComputeStats(top), nil
+}
+
+func ComputeConcentration(blockchain core.Blockchain, globPattern string,
+	start, end uint64, timeRange core.TimeRange, filter *core.Filter,
+	duration core.Duration, by core.ActionProperties, topN []int,
+) (*core.TimeGroupedConcentration, error) {
+	result := core.NewTimeGroupedConcentration(duration, by, topN)
+	err := AggregateBlocks(blockchain, globPattern, start, end, timeRange, filter, result)
+	return result, err
+}
+
+func CountProducersOverTime(blockchain core.Blockchain, globPattern string,
+	start, end uint64, timeRange core.TimeRange, duration core.Duration, topN []int,
+) (*core.ProducersOverTime, error) {
+	result := core.NewProducersOverTime(duration, topN)
+	err := AggregateBlocks(blockchain, globPattern, start, end, timeRange, nil, result)
+	return result, err
+}
+
+func ComputeBlockTimes(blockchain core.Blockchain, globPattern string,
+	start, end uint64, timeRange core.TimeRange, duration core.Duration, stallFactor float64,
+) (*core.BlockTimes, error) {
+	result := core.NewBlockTimes(duration, stallFactor)
+	err := AggregateBlocks(blockchain, globPattern, start, end, timeRange, nil, result)
+	return result, err
+}
+
+func ComputeDistribution(blockchain core.Blockchain, globPattern string,
+	start, end uint64, timeRange core.TimeRange, filter *core.Filter, duration core.Duration,
+) (*core.Distribution, error) {
+	result := core.NewDistribution(duration)
+	err := AggregateBlocks(blockchain, globPattern, start, end, timeRange, filter, result)
+	return result, err
+}
+
+func ComputeTransferVolume(blockchain core.Blockchain, globPattern string,
+	start, end uint64, timeRange core.TimeRange, filter *core.Filter,
+	duration core.Duration, accountsLimit int,
+) (*core.TransferVolume, error) {
+	result := core.NewTransferVolume(duration).SetAccountsLimit(accountsLimit)
+	err := AggregateBlocks(blockchain, globPattern, start, end, timeRange, filter, result)
+	return result, err
+}
+
+func ComputeFailureRateOverTime(blockchain core.Blockchain, globPattern string,
+	start, end uint64, timeRange core.TimeRange, filter *core.Filter, duration core.Duration,
+) (*core.FailureRateOverTime, error) {
+	result := core.NewFailureRateOverTime(duration)
+	err := AggregateBlocks(blockchain, globPattern, start, end, timeRange, filter, result)
+	return result, err
+}
+
+func ComputeInlineActionsOverTime(blockchain core.Blockchain, globPattern string,
+	start, end uint64, timeRange core.TimeRange, filter *core.Filter, duration core.Duration,
+) (*core.InlineActionsOverTime, error) {
+	result := core.NewInlineActionsOverTime(duration)
+	err := AggregateBlocks(blockchain, globPattern, start, end, timeRange, filter, result)
+	return result, err
+}
+
+func ComputeFeesOverTime(blockchain core.Blockchain, globPattern string,
+	start, end uint64, timeRange core.TimeRange, filter *core.Filter,
+	duration core.Duration, topSenders int,
+) (*core.FeesOverTime, error) {
+	result := core.NewFeesOverTime(duration).SetTopSenders(topSenders)
+	err := AggregateBlocks(blockchain, globPattern, start, end, timeRange, filter, result)
+	return result, err
+}
+
+func ComputeActionsPerTransaction(blockchain core.Blockchain, globPattern string,
+	start, end uint64, timeRange core.TimeRange, filter *core.Filter, topPatterns int,
+) (*core.ActionsPerTransaction, error) {
+	result := core.NewActionsPerTransaction().SetPatternsLimit(topPatterns)
+	err := AggregateBlocks(blockchain, globPattern, start, end, timeRange, filter, result)
+	return result, err
+}
+
+// ReplayBalances replays the transfers of asset on top of the initial balances
+// and takes snapshots after the given block heights or, if none, every duration
+// Snapshots contain the selected accounts or, if none, the top accounts by balance
+func ReplayBalances(blockchain core.Blockchain, globPattern string,
+	start, end uint64, timeRange core.TimeRange, asset string,
+	initial map[string]*big.Rat, duration core.Duration, heights []uint64,
+	accounts []string, top int,
+) (*core.Balances, error) {
+	result := core.NewBalances(asset, initial).SetAccounts(accounts, top)
+	if len(heights) > 0 {
+		result.SnapshotAt(heights)
+	} else {
+		result.SnapshotEvery(duration)
 	}
-	return groupedActions, nil
+	err := AggregateBlocks(blockchain, globPattern, start, end, timeRange, nil, result)
+	return result, err
 }
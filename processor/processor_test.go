@@ -1,7 +1,15 @@
 package processor
 
 import (
+	"encoding/json"
+	"fmt"
 	"io"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -46,7 +54,7 @@ func TestGetMissingBlockNumbersInvalid(t *testing.T) {
 func TestCountTransactions(t *testing.T) {
 	blockchain := xrp.New()
 	filepath := core.GetFixture(core.XRPValidLedgersFilename)
-	count, err := CountTransactions(blockchain, filepath, uint64(0), uint64(0))
+	count, err := CountTransactions(blockchain, filepath, uint64(0), uint64(0), core.TimeRange{}, nil)
 	assert.Nil(t, err)
 	assert.Equal(t, 4518, count)
 }
@@ -67,7 +75,7 @@ func TestCountActionsOverTime(t *testing.T) {
 	blockchain := xrp.New()
 	filepath := core.GetFixture(core.XRPValidLedgersFilename)
 	actionsCount, err := CountActionsOverTime(
-		blockchain, filepath, uint64(0), uint64(0), time.Minute, core.ActionName)
+		blockchain, filepath, uint64(0), uint64(0), core.TimeRange{}, nil, core.NewDuration(time.Minute), core.ActionProperties{core.ActionName})
 	assert.Nil(t, err)
 	assert.Len(t, actionsCount.Actions, 7)
 	lastGroup := time.Date(2020, 3, 27, 20, 55, 0, 0, time.UTC)
@@ -82,7 +90,7 @@ func TestCountTransactionsOverTime(t *testing.T) {
 	blockchain := xrp.New()
 	filepath := core.GetFixture(core.XRPValidLedgersFilename)
 	actionsCount, err := CountTransactionsOverTime(
-		blockchain, filepath, uint64(0), uint64(0), time.Minute)
+		blockchain, filepath, uint64(0), uint64(0), core.TimeRange{}, nil, core.NewDuration(time.Minute))
 	assert.Nil(t, err)
 	assert.Len(t, actionsCount.TransactionCounts, 7)
 	lastGroup := time.Date(2020, 3, 27, 20, 55, 0, 0, time.UTC)
@@ -95,8 +103,646 @@ func TestGroupActions(t *testing.T) {
 	blockchain := xrp.New()
 	filepath := core.GetFixture(core.XRPValidLedgersFilename)
 	actionsCount, err := GroupActions(
-		blockchain, filepath, uint64(0), uint64(0), core.ActionName, false)
+		blockchain, filepath, uint64(0), uint64(0), core.TimeRange{}, nil, core.ActionProperties{core.ActionName}, false)
 	assert.Nil(t, err)
 	assert.Equal(t, uint64(1129), actionsCount.GetCount("Payment"))
 	assert.Equal(t, uint64(3088), actionsCount.GetCount("OfferCreate"))
 }
+
+func TestFilterFilesByTime(t *testing.T) {
+	files := []string{"eos-2020-02.jsonl", "eos-2020-03.jsonl", "eos-2020-04-01.jsonl", "eos-1--100.jsonl"}
+	timeRange := core.NewTimeRange(
+		time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2020, 4, 1, 0, 0, 0, 0, time.UTC))
+	assert.Equal(t, []string{"eos-2020-03.jsonl", "eos-1--100.jsonl"}, FilterFilesByTime(files, timeRange))
+	assert.Equal(t, files, FilterFilesByTime(files, core.TimeRange{}))
+}
+
+func TestPartitionByTime(t *testing.T) {
+	outputDir, err := ioutil.TempDir("", "partition")
+	assert.Nil(t, err)
+	defer os.RemoveAll(outputDir)
+
+	blockchain := xrp.New()
+	fixtures := core.GetFixture(core.XRPValidLedgersFilename)
+	err = PartitionByTime(blockchain, fixtures, 0, 0, core.TimeRange{},
+		core.DailyPartition, path.Join(outputDir, "xrp.jsonl.gz"))
+	assert.Nil(t, err)
+
+	files, err := filepath.Glob(path.Join(outputDir, "*"))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{path.Join(outputDir, "xrp-2020-03-27.jsonl.gz")}, files)
+
+	count, err := CountTransactions(blockchain, files[0], 0, 0, core.TimeRange{}, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 4518, count)
+
+	timeRange := core.NewTimeRange(time.Date(2020, 3, 28, 0, 0, 0, 0, time.UTC), time.Time{})
+	blocks, err := YieldAllBlocksInRange(path.Join(outputDir, "*"), blockchain, 0, 0, timeRange)
+	assert.Nil(t, err)
+	_, ok := <-blocks
+	assert.False(t, ok)
+}
+
+func TestPartitionByTimeErrors(t *testing.T) {
+	outputDir, err := ioutil.TempDir("", "partition")
+	assert.Nil(t, err)
+	defer os.RemoveAll(outputDir)
+
+	blockchain := xrp.New()
+	fixtures := core.GetFixture(core.XRPValidLedgersFilename)
+	err = PartitionByTime(blockchain, fixtures, 0, 0, core.TimeRange{},
+		core.DailyPartition, path.Join(outputDir, "xrp.dat"))
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "do not have the same format")
+	}
+
+	broken := path.Join(outputDir, "broken.jsonl.gz")
+	assert.Nil(t, ioutil.WriteFile(broken, []byte("not gzip"), 0644))
+	err = PartitionByTime(blockchain, path.Join(outputDir, "*.jsonl.gz"), 0, 0, core.TimeRange{},
+		core.DailyPartition, path.Join(outputDir, "output", "xrp.jsonl.gz"))
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), broken)
+	}
+}
+
+func TestCountTransactionsInTimeRange(t *testing.T) {
+	blockchain := xrp.New()
+	filepath := core.GetFixture(core.XRPValidLedgersFilename)
+	timeRange := core.NewTimeRange(
+		time.Date(2020, 3, 27, 20, 55, 0, 0, time.UTC), time.Time{})
+	count, err := CountTransactions(blockchain, filepath, uint64(0), uint64(0), timeRange, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 451, count)
+}
+
+func TestResolveTime(t *testing.T) {
+	blockchain := xrp.New()
+	filepath := core.GetFixture(core.XRPValidLedgersFilename)
+	blockTime, err := ResolveTime(blockchain, filepath, 0, 0,
+		time.Date(2020, 3, 27, 20, 52, 50, 0, time.UTC))
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(54387329), blockTime.Number)
+	blockTime, err = ResolveTime(blockchain, filepath, 0, 0,
+		time.Date(2020, 3, 27, 20, 52, 51, 0, time.UTC))
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(54387330), blockTime.Number)
+	_, err = ResolveTime(blockchain, filepath, 0, 0,
+		time.Date(2020, 3, 28, 0, 0, 0, 0, time.UTC))
+	assert.NotNil(t, err)
+}
+
+func TestResolveTimePrunesFiles(t *testing.T) {
+	outputDir, err := ioutil.TempDir("", "resolve")
+	assert.Nil(t, err)
+	defer os.RemoveAll(outputDir)
+
+	blockchain := xrp.New()
+	fixtures := core.GetFixture(core.XRPValidLedgersFilename)
+	from := time.Date(2020, 3, 27, 20, 54, 0, 0, time.UTC)
+	err = PartitionByTime(blockchain, fixtures, 0, 0, core.NewTimeRange(from, time.Time{}),
+		core.DailyPartition, path.Join(outputDir, "xrp.jsonl.gz"))
+	assert.Nil(t, err)
+	// all the blocks in a file partitioned on the previous day, which should never be read
+	content, err := ioutil.ReadFile(fixtures)
+	assert.Nil(t, err)
+	assert.Nil(t, ioutil.WriteFile(path.Join(outputDir, "xrp-2020-03-26.jsonl.gz"), content, 0644))
+
+	index, err := IndexBlockTimes(blockchain, fixtures, 0, 0)
+	assert.Nil(t, err)
+	expected, err := FindFirstBlockAt(index, from)
+	assert.Nil(t, err)
+	blockTime, err := ResolveTime(blockchain, path.Join(outputDir, "*"), 0, 0,
+		time.Date(2020, 3, 27, 20, 52, 50, 0, time.UTC))
+	assert.Nil(t, err)
+	assert.Equal(t, expected.Number, blockTime.Number)
+}
+
+type timedBlock struct {
+	core.Block
+	blockTime BlockTime
+}
+
+func (b timedBlock) Number() uint64 {
+	return b.blockTime.Number
+}
+
+func (b timedBlock) Time() time.Time {
+	return b.blockTime.Time
+}
+
+type indexFetcher []BlockTime
+
+func (f indexFetcher) FetchBlock(number uint64) (core.Block, error) {
+	for _, blockTime := range f {
+		if blockTime.Number == number {
+			return timedBlock{blockTime: blockTime}, nil
+		}
+	}
+	return nil, fmt.Errorf("block %d not found", number)
+}
+
+func TestResolveTimeFromNode(t *testing.T) {
+	blockchain := xrp.New()
+	filepath := core.GetFixture(core.XRPValidLedgersFilename)
+	index, err := IndexBlockTimes(blockchain, filepath, 0, 0)
+	assert.Nil(t, err)
+	assert.Len(t, index, 100)
+	at := time.Date(2020, 3, 27, 20, 54, 0, 0, time.UTC)
+	expected, err := FindFirstBlockAt(index, at)
+	assert.Nil(t, err)
+	blockTime, err := ResolveTimeFromNode(indexFetcher(index), index[0].Number, index[99].Number, at)
+	assert.Nil(t, err)
+	assert.Equal(t, expected.Number, blockTime.Number)
+}
+
+func TestGroupActionsWithFilter(t *testing.T) {
+	blockchain := xrp.New()
+	filepath := core.GetFixture(core.XRPValidLedgersFilename)
+	filter, err := core.ParseFilter(`name in ["Payment", "OfferCancel"]`)
+	assert.Nil(t, err)
+	actionsCount, err := GroupActions(
+		blockchain, filepath, uint64(0), uint64(0), core.TimeRange{}, filter, core.ActionProperties{core.ActionName}, false)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1129), actionsCount.GetCount("Payment"))
+	assert.Equal(t, uint64(0), actionsCount.GetCount("OfferCreate"))
+}
+
+func TestBulkConfigFilter(t *testing.T) {
+	var config BulkConfig
+	rawConfig := `{"Processors": [{"Name": "Payments", "Type": "count-transactions", "Filter": "name == \"Payment\""}]}`
+	assert.Nil(t, json.Unmarshal([]byte(rawConfig), &config))
+	assert.Len(t, config.Processors, 1)
+	assert.Equal(t, `name == "Payment"`, config.Processors[0].Filter.String())
+
+	block, err := xrp.New().ParseBlock(core.ReadAllBlocks("xrp")[0])
+	assert.Nil(t, err)
+	payments := 0
+	for _, transaction := range block.ListTransactions() {
+		for _, action := range transaction.Actions {
+			if action.Name() == "Payment" {
+				payments++
+				break
+			}
+		}
+	}
+	assert.Less(t, payments, block.TransactionsCount())
+	config.Processors[0].AddBlock(block)
+	assert.Equal(t, payments, int(*config.Processors[0].Aggregator.(*core.TransactionCounter)))
+
+	rawConfig = `{"Processors": [{"Name": "Payments", "Type": "count-transactions", "Filter": "name == "}]}`
+	assert.NotNil(t, json.Unmarshal([]byte(rawConfig), &config))
+}
+
+func TestFilteredBlockTimes(t *testing.T) {
+	filter, err := core.ParseFilter(`name == "NoSuchAction"`)
+	assert.Nil(t, err)
+	blockTimes := core.NewBlockTimes(core.NewDuration(time.Hour), core.DefaultStallFactor)
+	blockTimesProcessor := NewFilteredProcessor("BlockTimes", blockTimes, filter)
+	blocks, err := YieldAllBlocks(core.GetFixture(core.XRPValidLedgersFilename), xrp.New(), 0, 0)
+	assert.Nil(t, err)
+	for block := range blocks {
+		blockTimesProcessor.AddBlock(block)
+	}
+	_, total, _ := blockTimes.Stats()
+	assert.Equal(t, 100, total.BlocksCount)
+	assert.Equal(t, total.BlocksCount, total.EmptyBlocksCount)
+	assert.Equal(t, 0, total.ActionsCount)
+	assert.Equal(t, total.BlocksCount-1, total.Intervals.Count)
+}
+
+func TestBulkConfigResultsLimits(t *testing.T) {
+	var config BulkConfig
+	rawConfig := `{"Processors": [{"Name": "BySender", "Type": "group-actions", "Params": {"By": "sender", "Top": 1}}]}`
+	assert.Nil(t, json.Unmarshal([]byte(rawConfig), &config))
+	block, err := xrp.New().ParseBlock(core.ReadAllBlocks("xrp")[0])
+	assert.Nil(t, err)
+	config.Processors[0].AddBlock(block)
+	rawResult, err := json.Marshal(config.Processors[0].Aggregator.Result())
+	assert.Nil(t, err)
+	var result struct {
+		Actions []core.NamedCount
+		Others  core.OthersCount
+	}
+	assert.Nil(t, json.Unmarshal(rawResult, &result))
+	assert.Len(t, result.Actions, 1)
+	assert.Equal(t, uint64(block.TransactionsCount()), result.Actions[0].Count+result.Others.Count)
+}
+
+func TestBulkConfigCategories(t *testing.T) {
+	var config BulkConfig
+	rawConfig := fmt.Sprintf(`{"Categories": [%q], "Processors": [
+		{"Name": "ByCategory", "Type": "group-actions-over-time", "Params": {"By": "category.name", "Duration": "1h"}}]}`,
+		core.GetFixture(core.CategoriesFilename))
+	assert.Nil(t, json.Unmarshal([]byte(rawConfig), &config))
+	block, err := xrp.New().ParseBlock(core.ReadAllBlocks("xrp")[0])
+	assert.Nil(t, err)
+	config.Processors[0].AddBlock(block)
+	grouped := config.Processors[0].Aggregator.(*core.TimeGroupedActions)
+	assert.Equal(t, "category.name", grouped.GroupedBy.String())
+	for _, actions := range grouped.Actions {
+		assert.Len(t, actions.Actions, 1)
+		assert.Equal(t, uint64(block.TransactionsCount()), actions.GetCount(core.DefaultCategory))
+	}
+
+	rawConfig = `{"Processors": [{"Name": "ByCategory", "Type": "group-actions", "Params": {"By": "category"}}]}`
+	var invalidConfig BulkConfig
+	assert.NotNil(t, json.Unmarshal([]byte(rawConfig), &invalidConfig))
+	categories, err := core.LoadCategories(core.GetFixture(core.CategoriesFilename))
+	assert.Nil(t, err)
+	config = *NewBulkConfig(categories)
+	assert.Nil(t, json.Unmarshal([]byte(rawConfig), &config))
+	config.Processors[0].AddBlock(block)
+	assert.Equal(t, uint64(block.TransactionsCount()),
+		config.Processors[0].Aggregator.(*core.GroupedActions).GetCount(core.DefaultCategory))
+
+	rawConfig = `{"Categories": ["missing.json"], "Processors": []}`
+	assert.NotNil(t, json.Unmarshal([]byte(rawConfig), &invalidConfig))
+}
+
+func TestApproxAggregatorsMatchGroupActions(t *testing.T) {
+	blockchain := xrp.New()
+	filepath := core.GetFixture(core.XRPValidLedgersFilename)
+	by := core.ActionProperties{core.ActionSender}
+	exact, err := GroupActions(blockchain, filepath, 0, 0, core.TimeRange{}, nil, by, false)
+	assert.Nil(t, err)
+
+	var config BulkConfig
+	rawConfig := `{"Processors": [
+		{"Name": "Unique", "Type": "approx-unique", "Params": {"By": "sender", "RelativeError": 0.01}},
+		{"Name": "Top", "Type": "heavy-hitters", "Params": {"By": "sender", "K": 50}}
+	]}`
+	assert.Nil(t, json.Unmarshal([]byte(rawConfig), &config))
+	config.Pattern = filepath
+	results, err := RunBulkActions(blockchain, config)
+	assert.Nil(t, err)
+	processorResults := results["Results"].(map[string]interface{})
+
+	unique := processorResults["Unique"].(*core.ApproxUniqueActions)
+	exactUnique := float64(len(exact.Actions))
+	assert.InDelta(t, exactUnique, unique.UniqueCount(), 3*0.01*exactUnique)
+	assert.Equal(t, exact.ActionsCount, unique.ActionsCount)
+
+	heavyHitters := processorResults["Top"].(*core.HeavyHitters)
+	top := heavyHitters.Top()
+	for _, counter := range top[:10] {
+		exactCount := exact.GetCount(counter.Name)
+		assert.LessOrEqual(t, exactCount, counter.Count)
+		assert.GreaterOrEqual(t, exactCount, counter.Count-counter.Error)
+	}
+	tracked := make(map[string]bool)
+	for _, counter := range top {
+		tracked[counter.Name] = true
+	}
+	for _, group := range exact.Actions {
+		if group.Count > exact.ActionsCount/50 {
+			assert.Contains(t, tracked, group.Name)
+		}
+	}
+}
+
+func TestCountActiveAccountsOverTime(t *testing.T) {
+	blockchain := xrp.New()
+	filepath := core.GetFixture(core.XRPValidLedgersFilename)
+	duration := core.NewDuration(time.Minute)
+	activeAccounts, err := CountActiveAccountsOverTime(
+		blockchain, filepath, uint64(0), uint64(0), core.TimeRange{}, nil, duration)
+	assert.Nil(t, err)
+	assert.Len(t, activeAccounts.Accounts, 7)
+
+	actionsCount, err := CountActionsOverTime(
+		blockchain, filepath, uint64(0), uint64(0), core.TimeRange{}, nil, duration,
+		core.ActionProperties{core.ActionSender})
+	assert.Nil(t, err)
+	lastGroup := time.Date(2020, 3, 27, 20, 55, 0, 0, time.UTC)
+	assert.Equal(t, len(actionsCount.Actions[lastGroup].Actions), activeAccounts.Accounts[lastGroup].SendersCount())
+	assert.GreaterOrEqual(t, activeAccounts.Accounts[lastGroup].ParticipantsCount(),
+		activeAccounts.Accounts[lastGroup].SendersCount())
+}
+
+func TestCountNewAccountsOverTime(t *testing.T) {
+	outputDir, err := ioutil.TempDir("", "new-accounts")
+	assert.Nil(t, err)
+	defer os.RemoveAll(outputDir)
+	exportPath := path.Join(outputDir, "accounts.csv")
+
+	blockchain := xrp.New()
+	filepath := core.GetFixture(core.XRPValidLedgersFilename)
+	day, _ := core.ParseDuration("day")
+	newAccounts, err := CountNewAccountsOverTime(
+		blockchain, filepath, uint64(0), uint64(0), core.TimeRange{}, nil, day, exportPath)
+	assert.Nil(t, err)
+
+	activeAccounts, err := CountActiveAccountsOverTime(
+		blockchain, filepath, uint64(0), uint64(0), core.TimeRange{}, nil, day)
+	assert.Nil(t, err)
+	group := time.Date(2020, 3, 27, 0, 0, 0, 0, time.UTC)
+	participantsCount := activeAccounts.Accounts[group].ParticipantsCount()
+	assert.Equal(t, map[time.Time]uint64{group: uint64(participantsCount)}, newAccounts.NewAccountsOverTime())
+
+	exported, err := ioutil.ReadFile(exportPath)
+	assert.Nil(t, err)
+	assert.Len(t, strings.Split(strings.TrimSpace(string(exported)), "\n"), participantsCount+1)
+}
+
+func TestExportGraph(t *testing.T) {
+	outputDir, err := ioutil.TempDir("", "graph")
+	assert.Nil(t, err)
+	defer os.RemoveAll(outputDir)
+	output := path.Join(outputDir, "graph.csv")
+
+	blockchain := xrp.New()
+	filepath := core.GetFixture(core.XRPValidLedgersFilename)
+	err = ExportGraph(blockchain, filepath, uint64(0), uint64(0), core.TimeRange{}, nil,
+		false, 2, core.CSVGraphFormat, output)
+	assert.Nil(t, err)
+
+	exported, err := ioutil.ReadFile(output)
+	assert.Nil(t, err)
+	lines := strings.Split(strings.TrimSpace(string(exported)), "\n")
+	assert.Greater(t, len(lines), 1)
+	assert.Equal(t, "sender,receiver,name,count,first_block,last_block", lines[0])
+	for _, line := range lines[1:] {
+		var count uint64
+		fmt.Sscanf(strings.Split(line, ",")[3], "%d", &count)
+		assert.GreaterOrEqual(t, count, uint64(2))
+	}
+}
+
+func TestExportTransfers(t *testing.T) {
+	outputDir, err := ioutil.TempDir("", "transfers")
+	assert.Nil(t, err)
+	defer os.RemoveAll(outputDir)
+	output := path.Join(outputDir, "transfers.csv")
+
+	blockchain := xrp.New()
+	filepath := core.GetFixture(core.XRPValidLedgersFilename)
+	err = ExportTransfers(blockchain, filepath, uint64(0), uint64(0), core.TimeRange{}, nil,
+		core.CSVTransferFormat, output)
+	assert.Nil(t, err)
+
+	exported, err := ioutil.ReadFile(output)
+	assert.Nil(t, err)
+	lines := strings.Split(strings.TrimSpace(string(exported)), "\n")
+	assert.Equal(t, strings.Join(core.TransferColumns, ","), lines[0])
+	// failed payments are not exported
+	assert.Len(t, lines, 283)
+
+	output = path.Join(outputDir, "transfers.parquet")
+	err = ExportTransfers(blockchain, filepath, uint64(0), uint64(0), core.TimeRange{}, nil,
+		core.ParquetTransferFormat, output)
+	assert.Nil(t, err)
+	exported, err = ioutil.ReadFile(output)
+	assert.Nil(t, err)
+	assert.Equal(t, "PAR1", string(exported[:4]))
+	assert.Equal(t, "PAR1", string(exported[len(exported)-4:]))
+}
+
+func TestBulkConfigConcentration(t *testing.T) {
+	var config BulkConfig
+	rawConfig := `{"Processors": [{"Name": "Concentration", "Type": "concentration", "Params": {"Duration": "day"}}]}`
+	assert.Nil(t, json.Unmarshal([]byte(rawConfig), &config))
+	concentration := config.Processors[0].Aggregator.(*core.TimeGroupedConcentration)
+	assert.Equal(t, core.DefaultConcentrationProperties, concentration.GroupedBy)
+	assert.Equal(t, core.DefaultConcentrationTopN, concentration.TopN)
+
+	var invalidConfig BulkConfig
+	rawConfig = `{"Processors": [{"Name": "Concentration", "Type": "concentration", "Params": {}}]}`
+	assert.NotNil(t, json.Unmarshal([]byte(rawConfig), &invalidConfig))
+}
+
+func TestBulkConfigRequiresDuration(t *testing.T) {
+	processorTypes := []string{
+		"count-transactions-over-time", "group-actions-over-time", "active-accounts-over-time",
+		"new-accounts-over-time", "retention", "concentration", "producers", "block-times",
+		"failure-rate-over-time", "inline-actions-over-time", "fees-over-time",
+	}
+	for _, processorType := range processorTypes {
+		var config BulkConfig
+		rawConfig := fmt.Sprintf(`{"Processors": [{"Name": "P", "Type": "%s", "Params": {"Duration": "day"}}]}`, processorType)
+		assert.Nil(t, json.Unmarshal([]byte(rawConfig), &config), processorType)
+
+		var invalidConfig BulkConfig
+		rawConfig = fmt.Sprintf(`{"Processors": [{"Name": "P", "Type": "%s", "Params": {}}]}`, processorType)
+		assert.EqualError(t, json.Unmarshal([]byte(rawConfig), &invalidConfig),
+			"processor P requires a duration", processorType)
+	}
+
+	var config BulkConfig
+	rawConfig := `{"Processors": [{"Name": "Distribution", "Type": "distribution", "Params": {}}]}`
+	assert.Nil(t, json.Unmarshal([]byte(rawConfig), &config))
+}
+
+// TestComputeHelpers runs the Compute helpers over the XRP fixture
+// and checks their results, sometimes against other helpers
+func TestComputeHelpers(t *testing.T) {
+	blockchain := xrp.New()
+	filepath := core.GetFixture(core.XRPValidLedgersFilename)
+	minute := core.NewDuration(time.Minute)
+	failures, err := core.ParseFilter(`status == "failure"`)
+	assert.Nil(t, err)
+
+	cases := []struct {
+		name    string
+		compute func() (interface{}, error)
+		check   func(t *testing.T, result interface{})
+	}{
+		{
+			name: "GraphStats",
+			compute: func() (interface{}, error) {
+				return ComputeGraphStats(blockchain, filepath, 0, 0, core.TimeRange{}, nil, 10)
+			},
+			check: func(t *testing.T, result interface{}) {
+				stats := result.(*core.GraphStats)
+				assert.Greater(t, stats.NodesCount, 0)
+				assert.Len(t, stats.TopPageRank, 10)
+				nodesCount := 0
+				for _, count := range stats.InDegreeDistribution {
+					nodesCount += count.Count
+				}
+				assert.Equal(t, stats.NodesCount, nodesCount)
+			},
+		},
+		{
+			name: "Retention",
+			compute: func() (interface{}, error) {
+				return ComputeRetention(blockchain, filepath, 0, 0, core.TimeRange{}, nil, minute)
+			},
+			check: func(t *testing.T, result interface{}) {
+				cohorts := result.(*core.Retention).Cohorts()
+				assert.Len(t, cohorts, 7)
+				activeAccounts, err := CountActiveAccountsOverTime(
+					blockchain, filepath, 0, 0, core.TimeRange{}, nil, minute)
+				assert.Nil(t, err)
+				for i, cohort := range cohorts {
+					activeCount := uint64(0)
+					for j, previous := range cohorts[:i+1] {
+						activeCount += previous.Active[i-j]
+					}
+					assert.Equal(t, uint64(activeAccounts.Accounts[cohort.Cohort].SendersCount()), activeCount)
+				}
+			},
+		},
+		{
+			name: "Concentration",
+			compute: func() (interface{}, error) {
+				return ComputeConcentration(blockchain, filepath, 0, 0, core.TimeRange{}, nil,
+					minute, core.DefaultConcentrationProperties, []int{1, 10})
+			},
+			check: func(t *testing.T, result interface{}) {
+				metrics := result.(*core.TimeGroupedConcentration).Metrics()
+				assert.Len(t, metrics, 7)
+				groupedActions, err := CountActionsOverTime(blockchain, filepath, 0, 0, core.TimeRange{}, nil,
+					minute, core.ActionProperties{core.ActionSender})
+				assert.Nil(t, err)
+				for group, actions := range groupedActions.Actions {
+					senders := metrics[group]["sender"]
+					assert.Equal(t, len(actions.Actions), senders.UniqueCount)
+					assert.Equal(t, actions.ActionsCount, senders.TotalCount)
+					assert.GreaterOrEqual(t, senders.TopShares[10], senders.TopShares[1])
+					assert.True(t, senders.Gini >= 0 && senders.Gini < 1)
+				}
+			},
+		},
+		{
+			name: "BlockTimes",
+			compute: func() (interface{}, error) {
+				return ComputeBlockTimes(blockchain, filepath, 0, 0, core.TimeRange{}, minute, core.DefaultStallFactor)
+			},
+			check: func(t *testing.T, result interface{}) {
+				stats, total, stalls := result.(*core.BlockTimes).Stats()
+				assert.Len(t, stats, 7)
+				assert.Equal(t, 100, total.BlocksCount)
+				assert.Equal(t, 4518, total.TransactionsCount)
+				assert.Equal(t, 99, total.Intervals.Count)
+				assert.True(t, total.Intervals.Min <= total.Intervals.P50 && total.Intervals.P50 <= total.Intervals.Max)
+				assert.Greater(t, total.TransactionsPerSecond, 0.0)
+				assert.Empty(t, stalls)
+			},
+		},
+		{
+			name: "Distribution",
+			compute: func() (interface{}, error) {
+				return ComputeDistribution(blockchain, filepath, 0, 0, core.TimeRange{}, nil, minute)
+			},
+			check: func(t *testing.T, result interface{}) {
+				distribution := result.(*core.Distribution)
+				transactions := distribution.Total.Transactions.Summary()
+				assert.Equal(t, uint64(100), transactions.Count)
+				assert.Equal(t, uint64(4518), transactions.Total)
+				assert.Equal(t, uint64(16), transactions.Min)
+				assert.Equal(t, uint64(84), transactions.Max)
+				assert.InDelta(t, 44, transactions.P50, 3)
+				assert.Len(t, distribution.OverTime, 7)
+				var count uint64
+				for _, distributions := range distribution.OverTime {
+					count += distributions.Actions.Summary().Count
+				}
+				assert.Equal(t, uint64(100), count)
+			},
+		},
+		{
+			name: "TransferVolume",
+			compute: func() (interface{}, error) {
+				return ComputeTransferVolume(blockchain, filepath, 0, 0, core.TimeRange{}, nil, minute, 10)
+			},
+			check: func(t *testing.T, result interface{}) {
+				volume := result.(*core.TransferVolume)
+				total := volume.Total("XRP")
+				assert.Equal(t, uint64(160), total.Count)
+				assert.Equal(t, "5335752.137289", core.FormatDecimal(total.Amount))
+				var count uint64
+				for _, assets := range volume.OverTime() {
+					for _, assetVolume := range assets {
+						count += assetVolume.Count
+					}
+				}
+				// failed payments are not counted
+				assert.Equal(t, uint64(282), count)
+			},
+		},
+		{
+			name: "ReplayBalances",
+			compute: func() (interface{}, error) {
+				return ReplayBalances(blockchain, filepath, 0, 0, core.TimeRange{}, "XRP", nil,
+					core.NewDuration(time.Hour), nil, nil, 0)
+			},
+			check: func(t *testing.T, result interface{}) {
+				snapshots := result.(*core.Balances).Snapshots()
+				if assert.Len(t, snapshots, 1) {
+					total := new(big.Rat)
+					for _, balance := range snapshots[0].Balances {
+						value, ok := new(big.Rat).SetString(balance.Balance)
+						assert.True(t, ok)
+						total.Add(total, value)
+					}
+					assert.Equal(t, "0", core.FormatDecimal(total))
+					assert.Equal(t, snapshots[0].AccountsCount, len(snapshots[0].Balances))
+				}
+			},
+		},
+		{
+			name: "FailureRateOverTime",
+			compute: func() (interface{}, error) {
+				return ComputeFailureRateOverTime(blockchain, filepath, 0, 0, core.TimeRange{}, nil, minute)
+			},
+			check: func(t *testing.T, result interface{}) {
+				failureRate := result.(*core.FailureRateOverTime)
+				assert.Equal(t, uint64(4518), failureRate.Total.ActionsCount)
+				assert.Equal(t, uint64(3659), failureRate.Total.SuccessCount)
+				assert.Equal(t, uint64(859), failureRate.Total.FailureCount)
+				assert.Len(t, failureRate.OverTime, 7)
+			},
+		},
+		{
+			name: "FailureRateOverTimeFiltered",
+			compute: func() (interface{}, error) {
+				return ComputeFailureRateOverTime(blockchain, filepath, 0, 0, core.TimeRange{}, failures, minute)
+			},
+			check: func(t *testing.T, result interface{}) {
+				failureRate := result.(*core.FailureRateOverTime)
+				assert.Equal(t, uint64(859), failureRate.Total.ActionsCount)
+				assert.Equal(t, 1.0, failureRate.Total.FailureRate)
+			},
+		},
+		{
+			name: "FeesOverTime",
+			compute: func() (interface{}, error) {
+				return ComputeFeesOverTime(blockchain, filepath, 0, 0, core.TimeRange{}, nil, minute, 2)
+			},
+			check: func(t *testing.T, result interface{}) {
+				fees := result.(*core.FeesOverTime)
+				total := fees.Total["fee"].Summary()
+				assert.Equal(t, uint64(4518), total.Count)
+				assert.Equal(t, uint64(5099179), total.Total)
+				assert.Len(t, fees.OverTime, 7)
+				senders := fees.Senders("fee")
+				if assert.Len(t, senders, 2) {
+					assert.Equal(t, "rJb5KsHsDHF1YS5B5DU6QCkH5NsPaKQTcy", senders[0].Sender)
+					assert.Equal(t, uint64(1500000), senders[0].Total)
+				}
+			},
+		},
+		{
+			name: "ActionsPerTransaction",
+			compute: func() (interface{}, error) {
+				return ComputeActionsPerTransaction(blockchain, filepath, 0, 0, core.TimeRange{}, nil, 10)
+			},
+			check: func(t *testing.T, result interface{}) {
+				actionsPerTransaction := result.(*core.ActionsPerTransaction)
+				summary := actionsPerTransaction.Distribution.Summary()
+				assert.Equal(t, uint64(4518), summary.Count)
+				assert.Equal(t, uint64(1), summary.Max)
+				assert.Equal(t, uint64(0), actionsPerTransaction.MultiActionCount)
+				assert.Len(t, actionsPerTransaction.Patterns(), 0)
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			result, err := c.compute()
+			assert.Nil(t, err)
+			c.check(t, result)
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package processor
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/danhper/blockchain-analyzer/core"
+)
+
+type BlockTime struct {
+	Number uint64
+	Time   time.Time
+}
+
+// IndexBlockTimes returns the number and time of all the blocks, sorted by number
+func IndexBlockTimes(blockchain core.Blockchain, globPattern string, start, end uint64) ([]BlockTime, error) {
+	return IndexBlockTimesInRange(blockchain, globPattern, start, end, core.TimeRange{})
+}
+
+// IndexBlockTimesInRange returns the number and time of the blocks in the time range,
+// sorted by number, skipping time-partitioned files outside of the range
+func IndexBlockTimesInRange(blockchain core.Blockchain, globPattern string,
+	start, end uint64, timeRange core.TimeRange) ([]BlockTime, error) {
+	blocks, err := YieldAllBlocksInRange(globPattern, blockchain, start, end, timeRange)
+	if err != nil {
+		return nil, err
+	}
+	var index []BlockTime
+	for block := range blocks {
+		index = append(index, BlockTime{Number: block.Number(), Time: block.Time()})
+	}
+	sort.Slice(index, func(i, j int) bool {
+		return index[i].Number < index[j].Number
+	})
+	return index, nil
+}
+
+// FindFirstBlockAt returns the first block of the index with a time at or after t
+func FindFirstBlockAt(index []BlockTime, t time.Time) (BlockTime, error) {
+	i := sort.Search(len(index), func(i int) bool {
+		return !index[i].Time.Before(t)
+	})
+	if i == len(index) {
+		return BlockTime{}, fmt.Errorf("no block found at or after %s", t)
+	}
+	return index[i], nil
+}
+
+// ResolveTime returns the first block at or after t in the data matching globPattern
+// Only the blocks from t onwards are indexed, so time-partitioned files ending before t are not read
+func ResolveTime(blockchain core.Blockchain, globPattern string, start, end uint64, t time.Time) (BlockTime, error) {
+	index, err := IndexBlockTimesInRange(blockchain, globPattern, start, end, core.NewTimeRange(t, time.Time{}))
+	if err != nil {
+		return BlockTime{}, err
+	}
+	return FindFirstBlockAt(index, t)
+}
+
+// ResolveTimeFromNode returns the first block at or after t between start and
+// end inclusive by fetching blocks from the node of the blockchain
+func ResolveTimeFromNode(fetcher core.BlockFetcher, start, end uint64, t time.Time) (BlockTime, error) {
+	if end < start {
+		return BlockTime{}, fmt.Errorf("invalid range %d--%d", start, end)
+	}
+	var result BlockTime
+	var fetchErr error
+	offset := sort.Search(int(end-start+1), func(i int) bool {
+		if fetchErr != nil {
+			return true
+		}
+		number := start + uint64(i)
+		block, err := fetcher.FetchBlock(number)
+		if err != nil {
+			fetchErr = fmt.Errorf("could not fetch block %d: %s", number, err.Error())
+			return true
+		}
+		if block.Time().Before(t) {
+			return false
+		}
+		if result.Time.IsZero() || number < result.Number {
+			result = BlockTime{Number: number, Time: block.Time()}
+		}
+		return true
+	})
+	if fetchErr != nil {
+		return BlockTime{}, fetchErr
+	}
+	if uint64(offset) > end-start {
+		return BlockTime{}, fmt.Errorf("no block found at or after %s in %d--%d", t, start, end)
+	}
+	return result, nil
+}
@@ -0,0 +1,76 @@
+package tezos
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+)
+
+const (
+	base58Alphabet     = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+	addressBytesLength = 22
+	addressHashLength  = 20
+)
+
+// implicitPrefixes are the base58check prefixes of tz1, tz2 and tz3
+// addresses, indexed by the tag of their curve in binary addresses
+var implicitPrefixes = [][]byte{
+	{6, 161, 159},
+	{6, 161, 161},
+	{6, 161, 164},
+}
+
+var originatedPrefix = []byte{2, 90, 121}
+
+func base58CheckEncode(prefix, payload []byte) string {
+	data := append(append([]byte{}, prefix...), payload...)
+	firstHash := sha256.Sum256(data)
+	checksum := sha256.Sum256(firstHash[:])
+	data = append(data, checksum[:4]...)
+
+	value := new(big.Int).SetBytes(data)
+	base := big.NewInt(int64(len(base58Alphabet)))
+	remainder := new(big.Int)
+	var encoded []byte
+	for value.Sign() > 0 {
+		value.DivMod(value, base, remainder)
+		encoded = append(encoded, base58Alphabet[remainder.Int64()])
+	}
+	for _, b := range data {
+		if b != 0 {
+			break
+		}
+		encoded = append(encoded, base58Alphabet[0])
+	}
+	for i, j := 0, len(encoded)-1; i < j; i, j = i+1, j-1 {
+		encoded[i], encoded[j] = encoded[j], encoded[i]
+	}
+	return string(encoded)
+}
+
+// DecodeAddress returns the base58check address of a hex-encoded binary address,
+// as given in optimized Micheline, where implicit accounts are encoded as 0x00,
+// the curve tag and the key hash and originated contracts as 0x01, the contract
+// hash and a padding byte. Entrypoints following the address are ignored
+func DecodeAddress(rawAddress string) (string, error) {
+	address, err := hex.DecodeString(rawAddress)
+	if err != nil {
+		return "", err
+	}
+	if len(address) < addressBytesLength {
+		return "", fmt.Errorf("address %s is too short", rawAddress)
+	}
+	switch address[0] {
+	case 0:
+		tag := int(address[1])
+		if tag >= len(implicitPrefixes) {
+			return "", fmt.Errorf("unknown curve %d in address %s", tag, rawAddress)
+		}
+		return base58CheckEncode(implicitPrefixes[tag], address[2:2+addressHashLength]), nil
+	case 1:
+		return base58CheckEncode(originatedPrefix, address[1:1+addressHashLength]), nil
+	default:
+		return "", fmt.Errorf("unknown address type %d in %s", address[0], rawAddress)
+	}
+}
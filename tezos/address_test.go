@@ -0,0 +1,31 @@
+package tezos
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeAddress(t *testing.T) {
+	cases := []struct {
+		raw      string
+		expected string
+	}{
+		{"00008b21d13aab0d8e7253bd28827c238498a0590962", "tz1YKh8T79LAtWxX29N5VedCSmaZGw9LNVxQ"},
+		{"0002358cbffa97149631cfb999fa47f0035fb1ea8636", "tz3RDC3Jdn4j15J7bBHZd29EUee9gVB1CxD9"},
+		{"012351e48f7a77bdf72901dcc7352b24bad440c05600", "KT1BoXKVVDAWLNzxViExruoq1a16AT6vRmkM"},
+		// entrypoints following the address are ignored
+		{"012351e48f7a77bdf72901dcc7352b24bad440c056007472616e73666572", "KT1BoXKVVDAWLNzxViExruoq1a16AT6vRmkM"},
+	}
+	for _, c := range cases {
+		address, err := DecodeAddress(c.raw)
+		assert.Nil(t, err)
+		assert.Equal(t, c.expected, address)
+	}
+
+	for _, raw := range []string{"zz", "0000", "0003358cbffa97149631cfb999fa47f0035fb1ea8636",
+		"0202358cbffa97149631cfb999fa47f0035fb1ea8636"} {
+		_, err := DecodeAddress(raw)
+		assert.NotNil(t, err, raw)
+	}
+}
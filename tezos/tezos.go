@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"strconv"
 	"time"
 
 	jsoniter "github.com/json-iterator/go"
@@ -14,7 +15,10 @@ import (
 
 var json = jsoniter.ConfigCompatibleWithStandardLibrary
 
-const defaultRPCEndpoint string = "https://api.tezos.org.ua"
+const (
+	defaultRPCEndpoint string = "https://api.tezos.org.ua"
+	tezDecimals               = 6
+)
 
 type Tezos struct {
 	RPCEndpoint string
@@ -30,11 +34,32 @@ func (t *Tezos) FetchData(filepath string, start, end uint64) error {
 	return fetcher.FetchHTTPData(filepath, context)
 }
 
+func (t *Tezos) FetchBlock(number uint64) (core.Block, error) {
+	rawBlock, err := fetcher.FetchHTTPBlock(number, t.makeRequest)
+	if err != nil {
+		return nil, err
+	}
+	return t.ParseBlock(rawBlock)
+}
+
+type OperationResult struct {
+	Status string
+}
+
+type ContentMetadata struct {
+	OperationResult *OperationResult `json:"operation_result"`
+}
+
 type Content struct {
-	Kind        string
-	Source      string
-	Destination string
-	Amount      string
+	Kind         string
+	Source       string
+	Destination  string
+	Amount       string
+	Fee          string
+	GasLimit     string `json:"gas_limit"`
+	StorageLimit string `json:"storage_limit"`
+	Parameters   *ContentParameters
+	Metadata     ContentMetadata
 }
 
 type Operation struct {
@@ -45,11 +70,17 @@ type Operation struct {
 type BlockHeader struct {
 	Level           uint64
 	Timestamp       string
+	Priority        int
 	ParsedTimestamp time.Time
 }
 
+type BlockMetadata struct {
+	Baker string
+}
+
 type Block struct {
 	Header     BlockHeader
+	Metadata   BlockMetadata
 	Operations [][]Operation
 	actions    []core.Action
 }
@@ -90,6 +121,14 @@ func (b *Block) Time() time.Time {
 	return b.Header.ParsedTimestamp
 }
 
+func (b *Block) Producer() string {
+	return b.Metadata.Baker
+}
+
+func (b *Block) Priority() int {
+	return b.Header.Priority
+}
+
 func (b *Block) TransactionsCount() int {
 	total := 0
 	for _, operations := range b.Operations {
@@ -114,6 +153,25 @@ func (b *Block) ListActions() []core.Action {
 	return result
 }
 
+// ListTransactions returns the operations of all the validation passes
+// The signer is the source of the operation, which is empty for endorsements
+func (b *Block) ListTransactions() []core.Transaction {
+	var transactions []core.Transaction
+	for _, operations := range b.Operations {
+		for _, operation := range operations {
+			transaction := core.Transaction{ID: operation.Hash, Index: len(transactions)}
+			for _, content := range operation.Contents {
+				if transaction.Signer == "" {
+					transaction.Signer = content.Source
+				}
+				transaction.Actions = append(transaction.Actions, content)
+			}
+			transactions = append(transactions, transaction)
+		}
+	}
+	return transactions
+}
+
 func (c Content) Name() string {
 	return c.Kind
 }
@@ -125,3 +183,50 @@ func (c Content) Receiver() string {
 func (c Content) Sender() string {
 	return c.Source
 }
+
+// Status returns the status of the operation result of manager operations
+// Operations without result, such as endorsements, are always applied
+func (c Content) Status() core.Status {
+	if c.Metadata.OperationResult == nil {
+		return core.StatusSuccess
+	}
+	switch c.Metadata.OperationResult.Status {
+	case "applied":
+		return core.StatusSuccess
+	case "failed", "backtracked", "skipped":
+		return core.StatusFailure
+	default:
+		return core.StatusUnknown
+	}
+}
+
+// Fees returns the fee, in mutez, and the gas and storage limits
+// of manager operations and nothing for the other operations
+func (c Content) Fees() []core.Fee {
+	if c.Fee == "" {
+		return nil
+	}
+	var fees []core.Fee
+	for _, resource := range []struct{ name, value string }{
+		{"fee", c.Fee}, {"gas_limit", c.GasLimit}, {"storage_limit", c.StorageLimit},
+	} {
+		value, err := strconv.ParseUint(resource.value, 10, 64)
+		if err != nil {
+			continue
+		}
+		fees = append(fees, core.Fee{Resource: resource.name, Value: value})
+	}
+	return fees
+}
+
+// Transfer returns the amount of tez, given in mutez, sent by applied transactions
+func (c Content) Transfer() (core.Transfer, bool) {
+	if c.Kind != "transaction" || c.Status() == core.StatusFailure {
+		return core.Transfer{}, false
+	}
+	amount, err := core.NewAmountFromUnits(c.Amount, tezDecimals, "XTZ")
+	if err != nil {
+		return core.Transfer{}, false
+	}
+	return core.Transfer{From: c.Source, To: c.Destination, Amount: amount}, true
+}
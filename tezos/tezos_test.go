@@ -18,6 +18,8 @@ func TestParseBlock(t *testing.T) {
 
 	expectedTime := time.Date(2018, 7, 7, 17, 06, 27, 0, time.UTC)
 	assert.Equal(t, expectedTime, block.Time())
+	assert.Equal(t, "tz3RDC3Jdn4j15J7bBHZd29EUee9gVB1CxD9", block.Producer())
+	assert.Equal(t, 0, block.(core.PriorityBlock).Priority())
 }
 
 func TestListActions(t *testing.T) {
@@ -26,3 +28,52 @@ func TestListActions(t *testing.T) {
 	actions := block.ListActions()
 	assert.Len(t, actions, 9)
 }
+
+func TestContentTransfer(t *testing.T) {
+	content := Content{Kind: "transaction", Source: "tz1a", Destination: "tz1b", Amount: "1500001"}
+	transfer, ok := content.Transfer()
+	assert.True(t, ok)
+	assert.Equal(t, "tz1a", transfer.From)
+	assert.Equal(t, "1.500001 XTZ", transfer.Amount.String())
+
+	_, ok = Content{Kind: "endorsement"}.Transfer()
+	assert.False(t, ok)
+}
+
+func TestContentStatus(t *testing.T) {
+	rawBlock := core.ReadAllBlocks("tezos")[1]
+	block, _ := New().ParseBlock(rawBlock)
+	for _, action := range block.ListActions() {
+		assert.Equal(t, core.StatusSuccess, action.Status())
+	}
+
+	failed := Content{Kind: "transaction", Amount: "1",
+		Metadata: ContentMetadata{OperationResult: &OperationResult{Status: "backtracked"}}}
+	assert.Equal(t, core.StatusFailure, failed.Status())
+	_, ok := failed.Transfer()
+	assert.False(t, ok)
+}
+
+func TestContentFees(t *testing.T) {
+	content := Content{Kind: "transaction", Fee: "1420", GasLimit: "10307", StorageLimit: "0"}
+	assert.Equal(t, []core.Fee{
+		{Resource: "fee", Value: 1420},
+		{Resource: "gas_limit", Value: 10307},
+		{Resource: "storage_limit", Value: 0},
+	}, content.Fees())
+	assert.Nil(t, Content{Kind: "endorsement"}.Fees())
+}
+
+func TestBlockListTransactions(t *testing.T) {
+	rawBlock := core.ReadAllBlocks("tezos")[1]
+	block, _ := New().ParseBlock(rawBlock)
+	transactions := block.ListTransactions()
+	assert.Len(t, transactions, block.TransactionsCount())
+	actionsCount := 0
+	for i, transaction := range transactions {
+		assert.Equal(t, i, transaction.Index)
+		assert.NotEmpty(t, transaction.ID)
+		actionsCount += len(transaction.Actions)
+	}
+	assert.Equal(t, 9, actionsCount)
+}
@@ -0,0 +1,159 @@
+package tezos
+
+import (
+	"bytes"
+
+	"github.com/danhper/blockchain-analyzer/core"
+)
+
+const (
+	fa12Asset = "FA1.2"
+	fa2Asset  = "FA2"
+)
+
+type ContentParameters struct {
+	Entrypoint string
+	Value      *Micheline
+}
+
+// Micheline is a Micheline expression, either a primitive application,
+// a literal or a sequence of expressions
+type Micheline struct {
+	Prim     string
+	Args     []*Micheline
+	String   string
+	Int      string
+	Bytes    string
+	Sequence []*Micheline
+}
+
+func (m *Micheline) UnmarshalJSON(b []byte) error {
+	if b = bytes.TrimSpace(b); len(b) > 0 && b[0] == '[' {
+		return json.Unmarshal(b, &m.Sequence)
+	}
+	type rawMicheline Micheline
+	return json.Unmarshal(b, (*rawMicheline)(m))
+}
+
+// pairArgs returns the arguments of nested pairs, as pairs can be given
+// either as right combs, e.g. Pair a (Pair b c), or directly as Pair a b c
+func (m *Micheline) pairArgs() []*Micheline {
+	if m == nil || m.Prim != "Pair" || len(m.Args) == 0 {
+		return []*Micheline{m}
+	}
+	args := append([]*Micheline{}, m.Args[:len(m.Args)-1]...)
+	return append(args, m.Args[len(m.Args)-1].pairArgs()...)
+}
+
+// address returns the address given either as a string or as bytes
+func (m *Micheline) address() (string, bool) {
+	if m == nil {
+		return "", false
+	}
+	if m.String != "" {
+		return m.String, true
+	}
+	if m.Bytes != "" {
+		address, err := DecodeAddress(m.Bytes)
+		return address, err == nil
+	}
+	return "", false
+}
+
+func (m *Micheline) integer() (string, bool) {
+	if m == nil || m.Int == "" {
+		return "", false
+	}
+	return m.Int, true
+}
+
+// TokenTransfers returns the transfers of applied calls to the transfer entrypoint
+// of FA1.2 and FA2 contracts, where amounts are given in token units and the issuer
+// is the token contract. Addresses can be given as strings or as bytes, as in optimized parameters
+func (c Content) TokenTransfers() []core.Transfer {
+	if c.Kind != "transaction" || c.Parameters == nil || c.Parameters.Entrypoint != "transfer" ||
+		c.Status() == core.StatusFailure || c.Parameters.Value == nil {
+		return nil
+	}
+	value := c.Parameters.Value
+	if value.Sequence != nil {
+		return c.fa2Transfers(value.Sequence)
+	}
+	args := value.pairArgs()
+	if len(args) != 3 {
+		return nil
+	}
+	from, okFrom := args[0].address()
+	to, okTo := args[1].address()
+	units, okUnits := args[2].integer()
+	if !okFrom || !okTo || !okUnits {
+		return nil
+	}
+	amount, err := core.NewAmount(units, fa12Asset, c.Destination)
+	if err != nil {
+		return nil
+	}
+	return []core.Transfer{{From: from, To: to, Amount: amount}}
+}
+
+// fa2Transfers parses a list of (from, list of (to, token_id, amount))
+// and uses FA2:<token_id> as asset
+func (c Content) fa2Transfers(batches []*Micheline) []core.Transfer {
+	var transfers []core.Transfer
+	for _, batch := range batches {
+		args := batch.pairArgs()
+		if len(args) != 2 || args[1] == nil {
+			continue
+		}
+		from, ok := args[0].address()
+		if !ok {
+			continue
+		}
+		for _, destination := range args[1].Sequence {
+			destinationArgs := destination.pairArgs()
+			if len(destinationArgs) != 3 {
+				continue
+			}
+			to, okTo := destinationArgs[0].address()
+			tokenID, okToken := destinationArgs[1].integer()
+			units, okUnits := destinationArgs[2].integer()
+			if !okTo || !okToken || !okUnits {
+				continue
+			}
+			amount, err := core.NewAmount(units, fa2Asset+":"+tokenID, c.Destination)
+			if err != nil {
+				continue
+			}
+			transfers = append(transfers, core.Transfer{From: from, To: to, Amount: amount})
+		}
+	}
+	return transfers
+}
+
+// ExtractTransfers returns the tez transfers and the FA1.2 and FA2 token transfers
+// of the block, contract calls without tez being skipped
+func (t *Tezos) ExtractTransfers(block core.Block) []core.TransferRecord {
+	var records []core.TransferRecord
+	for _, transaction := range block.ListTransactions() {
+		for _, action := range transaction.Actions {
+			content, ok := action.(Content)
+			if !ok {
+				continue
+			}
+			var transfers []core.Transfer
+			if transfer, ok := content.Transfer(); ok && transfer.Amount.Value.Sign() > 0 {
+				transfers = append(transfers, transfer)
+			}
+			transfers = append(transfers, content.TokenTransfers()...)
+			for _, transfer := range transfers {
+				records = append(records, core.TransferRecord{
+					Block:       block.Number(),
+					Time:        block.Time(),
+					Transaction: transaction.ID,
+					Transfer:    transfer,
+				})
+			}
+		}
+	}
+	return records
+}
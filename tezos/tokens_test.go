@@ -0,0 +1,94 @@
+package tezos
+
+import (
+	"testing"
+
+	"github.com/danhper/blockchain-analyzer/core"
+	"github.com/stretchr/testify/assert"
+)
+
+const fa12Transfer = `{
+	"kind": "transaction", "source": "tz1alice", "destination": "KT1token", "amount": "0",
+	"parameters": {"entrypoint": "transfer", "value": {"prim": "Pair", "args": [
+		{"string": "tz1alice"}, {"prim": "Pair", "args": [{"string": "tz1bob"}, {"int": "1500"}]}]}},
+	"metadata": {"operation_result": {"status": "applied"}}
+}`
+
+const fa2Transfer = `{
+	"kind": "transaction", "source": "tz1alice", "destination": "KT1nft", "amount": "0",
+	"parameters": {"entrypoint": "transfer", "value": [{"prim": "Pair", "args": [
+		{"string": "tz1alice"},
+		[{"prim": "Pair", "args": [{"string": "tz1bob"}, {"int": "3"}, {"int": "1"}]},
+		 {"prim": "Pair", "args": [{"string": "tz1carol"}, {"prim": "Pair", "args": [{"int": "4"}, {"int": "2"}]}]}]
+	]}]},
+	"metadata": {"operation_result": {"status": "applied"}}
+}`
+
+const optimizedFA12Transfer = `{
+	"kind": "transaction", "source": "tz1YKh8T79LAtWxX29N5VedCSmaZGw9LNVxQ", "destination": "KT1token",
+	"amount": "0",
+	"parameters": {"entrypoint": "transfer", "value": {"prim": "Pair", "args": [
+		{"bytes": "00008b21d13aab0d8e7253bd28827c238498a0590962"},
+		{"bytes": "012351e48f7a77bdf72901dcc7352b24bad440c05600"}, {"int": "7"}]}},
+	"metadata": {"operation_result": {"status": "applied"}}
+}`
+
+func parseContent(t *testing.T, rawContent string) Content {
+	var content Content
+	assert.Nil(t, json.Unmarshal([]byte(rawContent), &content))
+	return content
+}
+
+func TestTokenTransfersWithBytesAddresses(t *testing.T) {
+	transfers := parseContent(t, optimizedFA12Transfer).TokenTransfers()
+	if assert.Len(t, transfers, 1) {
+		assert.Equal(t, "tz1YKh8T79LAtWxX29N5VedCSmaZGw9LNVxQ", transfers[0].From)
+		assert.Equal(t, "KT1BoXKVVDAWLNzxViExruoq1a16AT6vRmkM", transfers[0].To)
+		assert.Equal(t, "7 FA1.2@KT1token", transfers[0].Amount.String())
+	}
+}
+
+func TestTokenTransfers(t *testing.T) {
+	transfers := parseContent(t, fa12Transfer).TokenTransfers()
+	if assert.Len(t, transfers, 1) {
+		assert.Equal(t, "tz1alice", transfers[0].From)
+		assert.Equal(t, "tz1bob", transfers[0].To)
+		assert.Equal(t, "1500 FA1.2@KT1token", transfers[0].Amount.String())
+	}
+
+	transfers = parseContent(t, fa2Transfer).TokenTransfers()
+	if assert.Len(t, transfers, 2) {
+		assert.Equal(t, "tz1bob", transfers[0].To)
+		assert.Equal(t, "1 FA2:3@KT1nft", transfers[0].Amount.String())
+		assert.Equal(t, "tz1carol", transfers[1].To)
+		assert.Equal(t, "2 FA2:4@KT1nft", transfers[1].Amount.String())
+	}
+
+	failed := parseContent(t, fa12Transfer)
+	failed.Metadata.OperationResult.Status = "backtracked"
+	assert.Empty(t, failed.TokenTransfers())
+	assert.Empty(t, parseContent(t, `{"kind": "transaction", "amount": "10"}`).TokenTransfers())
+}
+
+func TestExtractTransfers(t *testing.T) {
+	block := &Block{
+		Header: BlockHeader{Level: 10},
+		Operations: [][]Operation{{{
+			Hash:     "oo1",
+			Contents: []Content{parseContent(t, fa12Transfer), parseContent(t, fa2Transfer)},
+		}}},
+	}
+	var blockchain core.Blockchain = New()
+	records := core.ExtractTransfers(blockchain, block)
+	assert.Len(t, records, 3)
+	assert.Equal(t, "oo1", records[2].Transaction)
+	assert.Equal(t, uint64(10), records[2].Block)
+
+	blocks := core.ReadAllBlocks("tezos")
+	parsed, err := New().ParseBlock(blocks[0])
+	assert.Nil(t, err)
+	for _, record := range core.ExtractTransfers(blockchain, parsed) {
+		assert.Equal(t, "XTZ", record.Amount.Asset)
+		assert.Equal(t, 1, record.Amount.Value.Sign())
+	}
+}
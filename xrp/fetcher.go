@@ -238,17 +238,39 @@ func (c *XRPContext) Cleanup() error {
 	return nil
 }
 
-func fetchXRPData(filepath string, start, end uint64) error {
-	interrupt := make(chan os.Signal, 1)
-	signal.Notify(interrupt, os.Interrupt)
-
+func getWSURI() string {
 	wsURI := os.Getenv("XRP_WS_URI")
 	if wsURI == "" {
 		wsURI = defaultWSURI
 	}
+	return wsURI
+}
+
+func fetchXRPLedger(ledgerIndex uint64) (*Ledger, error) {
+	conn, _, err := websocket.DefaultDialer.Dial(getWSURI(), nil)
+	if err != nil {
+		return nil, &WSError{message: err.Error()}
+	}
+	defer func() {
+		closeConnection(conn)
+		conn.Close()
+	}()
+	if err := conn.WriteMessage(websocket.TextMessage, makeMessage(ledgerIndex)); err != nil {
+		return nil, &WSError{message: err.Error()}
+	}
+	_, message, err := conn.ReadMessage()
+	if err != nil {
+		return nil, &WSError{message: err.Error()}
+	}
+	return ParseRawLedger(message)
+}
+
+func fetchXRPData(filepath string, start, end uint64) error {
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
 
 	totalCount := end - start + 1
-	context, err := NewXRPContext(interrupt, wsURI, totalCount)
+	context, err := NewXRPContext(interrupt, getWSURI(), totalCount)
 	if err != nil {
 		log.Fatalln(err.Error())
 	}
@@ -1,6 +1,7 @@
 package xrp
 
 import (
+	"strconv"
 	"time"
 
 	jsoniter "github.com/json-iterator/go"
@@ -10,7 +11,10 @@ import (
 
 var json = jsoniter.ConfigCompatibleWithStandardLibrary
 
-const rippleEpochOffset int64 = 946684800
+const (
+	rippleEpochOffset int64 = 946684800
+	xrpDecimals             = 6
+)
 
 type XRP struct {
 }
@@ -19,10 +23,60 @@ func New() *XRP {
 	return &XRP{}
 }
 
+// CurrencyAmount is either an amount of XRP in drops, in which case
+// Currency and Issuer are empty, or an amount of an issued currency
+type CurrencyAmount struct {
+	Currency string
+	Issuer   string
+	Value    string
+}
+
+func (a *CurrencyAmount) UnmarshalJSON(b []byte) error {
+	if len(b) > 0 && b[0] == '"' {
+		*a = CurrencyAmount{}
+		return json.Unmarshal(b, &a.Value)
+	}
+	type rawAmount CurrencyAmount
+	return json.Unmarshal(b, (*rawAmount)(a))
+}
+
+func (a CurrencyAmount) ToAmount() (core.Amount, error) {
+	if a.Currency == "" {
+		return core.NewAmountFromUnits(a.Value, xrpDecimals, "XRP")
+	}
+	return core.NewAmount(a.Value, a.Currency, a.Issuer)
+}
+
+// TransactionMetadata is the result of a transaction, where the delivered amount
+// is given as delivered_amount by the API and as DeliveredAmount in older ledgers
+type TransactionMetadata struct {
+	TransactionResult  string
+	DeliveredAmount    *CurrencyAmount
+	APIDeliveredAmount *CurrencyAmount `json:"delivered_amount"`
+}
+
+// Delivered returns the amount actually delivered by a payment, which is lower
+// than its amount for partial payments, or nil if it is not available
+func (m *TransactionMetadata) Delivered() *CurrencyAmount {
+	if m == nil {
+		return nil
+	}
+	for _, amount := range []*CurrencyAmount{m.APIDeliveredAmount, m.DeliveredAmount} {
+		if amount != nil && amount.Value != "" && amount.Value != "unavailable" {
+			return amount
+		}
+	}
+	return nil
+}
+
 type Transaction struct {
+	Hash            string `json:"hash"`
 	Account         string
 	TransactionType string
 	Destination     string
+	Amount          *CurrencyAmount
+	Fee             string
+	MetaData        *TransactionMetadata `json:"metaData"`
 }
 
 type Ledger struct {
@@ -71,6 +125,10 @@ func (x *XRP) FetchData(filepath string, start, end uint64) error {
 	return fetchXRPData(filepath, start, end)
 }
 
+func (x *XRP) FetchBlock(number uint64) (core.Block, error) {
+	return fetchXRPLedger(number)
+}
+
 func (l *Ledger) Number() uint64 {
 	return l.Index
 }
@@ -79,6 +137,12 @@ func (l *Ledger) Time() time.Time {
 	return l.parsedCloseTime
 }
 
+// Producer returns an empty string as ledgers are validated
+// by consensus and do not contain validation data
+func (l *Ledger) Producer() string {
+	return ""
+}
+
 func (l *Ledger) TransactionsCount() int {
 	return len(l.Transactions)
 }
@@ -91,6 +155,20 @@ func (l *Ledger) ListActions() []core.Action {
 	return actions
 }
 
+// ListTransactions returns the transactions of the ledger, each with a single action
+func (l *Ledger) ListTransactions() []core.Transaction {
+	transactions := make([]core.Transaction, len(l.Transactions))
+	for i, t := range l.Transactions {
+		transactions[i] = core.Transaction{
+			ID:      t.Hash,
+			Index:   i,
+			Signer:  t.Account,
+			Actions: []core.Action{t},
+		}
+	}
+	return transactions
+}
+
 func (t Transaction) Sender() string {
 	return t.Account
 }
@@ -102,3 +180,42 @@ func (t Transaction) Receiver() string {
 func (t Transaction) Name() string {
 	return t.TransactionType
 }
+
+// Status returns success for tesSUCCESS results and failure for the other
+// results, e.g. tec codes for which only the fee was claimed
+func (t Transaction) Status() core.Status {
+	if t.MetaData == nil || t.MetaData.TransactionResult == "" {
+		return core.StatusUnknown
+	}
+	if t.MetaData.TransactionResult == "tesSUCCESS" {
+		return core.StatusSuccess
+	}
+	return core.StatusFailure
+}
+
+// Fees returns the fee in drops, which is destroyed even for failed transactions
+func (t Transaction) Fees() []core.Fee {
+	fee, err := strconv.ParseUint(t.Fee, 10, 64)
+	if err != nil {
+		return nil
+	}
+	return []core.Fee{{Resource: "fee", Value: fee}}
+}
+
+// Transfer returns the amount delivered by successful payments, falling back to
+// the amount of the payment when the delivered amount is not in the metadata,
+// as partial payments can deliver less than their amount
+func (t Transaction) Transfer() (core.Transfer, bool) {
+	if t.TransactionType != "Payment" || t.Amount == nil || t.Status() == core.StatusFailure {
+		return core.Transfer{}, false
+	}
+	currencyAmount := t.Amount
+	if delivered := t.MetaData.Delivered(); delivered != nil {
+		currencyAmount = delivered
+	}
+	amount, err := currencyAmount.ToAmount()
+	if err != nil {
+		return core.Transfer{}, false
+	}
+	return core.Transfer{From: t.Account, To: t.Destination, Amount: amount}, true
+}
@@ -36,3 +36,76 @@ func TestListActions(t *testing.T) {
 	actions := ledger.ListActions()
 	assert.Len(t, actions, 33)
 }
+
+func TestTransactionTransfer(t *testing.T) {
+	rawLedger := core.ReadAllBlocks("xrp")[0]
+	ledger, _ := ParseRawLedger(rawLedger)
+
+	_, ok := ledger.Transactions[0].Transfer()
+	assert.False(t, ok)
+
+	transfer, ok := ledger.Transactions[2].Transfer()
+	assert.True(t, ok)
+	assert.Equal(t, "rDsbeomae4FXwgQTJp9Rs64Qg9vDiTCdBv", transfer.From)
+	assert.Equal(t, "rEb8TK3gBgk5auZkwc6sHnwrGVJH8DuaLh", transfer.To)
+	assert.Equal(t, "6623.851471 XRP", transfer.Amount.String())
+
+	// tecPATH_DRY payments do not deliver anything
+	_, ok = ledger.Transactions[3].Transfer()
+	assert.False(t, ok)
+	amount, err := ledger.Transactions[3].Amount.ToAmount()
+	assert.Nil(t, err)
+	assert.Equal(t, "5001 ZCN@r8HgVGenRTAiNSM5iqt9PX2D2EczFZhZr", amount.String())
+}
+
+func TestPartialPaymentTransfer(t *testing.T) {
+	ledger, err := ParseRawLedger(core.ReadAllBlocks("xrp")[23])
+	assert.Nil(t, err)
+	var payment *Transaction
+	for i, transaction := range ledger.Transactions {
+		if transaction.Hash == "7D13BEA86C9D1A84B3172A219029CAA1FCB7D51FB45742FE9B40D49FAB1477B5" {
+			payment = &ledger.Transactions[i]
+		}
+	}
+	if assert.NotNil(t, payment) {
+		transfer, ok := payment.Transfer()
+		assert.True(t, ok)
+		assert.Equal(t, "1.311632 UPE@rMHSvqV83BhFDhkQtXELxNYyyhq776dhzG", transfer.Amount.String())
+		assert.Equal(t, "5000", payment.Amount.Value)
+	}
+
+	var transaction Transaction
+	rawTransaction := `{"Account": "a", "Destination": "b", "TransactionType": "Payment", "Amount": "1000",
+		"metaData": {"TransactionResult": "tesSUCCESS", "DeliveredAmount": "10"}}`
+	assert.Nil(t, json.Unmarshal([]byte(rawTransaction), &transaction))
+	transfer, ok := transaction.Transfer()
+	assert.True(t, ok)
+	assert.Equal(t, "0.00001 XRP", transfer.Amount.String())
+
+	transaction.MetaData = &TransactionMetadata{
+		TransactionResult:  "tesSUCCESS",
+		APIDeliveredAmount: &CurrencyAmount{Value: "unavailable"},
+	}
+	transfer, ok = transaction.Transfer()
+	assert.True(t, ok)
+	assert.Equal(t, "0.001 XRP", transfer.Amount.String())
+}
+
+func TestTransactionStatus(t *testing.T) {
+	rawLedger := core.ReadAllBlocks("xrp")[0]
+	ledger, _ := ParseRawLedger(rawLedger)
+	assert.Equal(t, core.StatusSuccess, ledger.Transactions[2].Status())
+	assert.Equal(t, core.StatusFailure, ledger.Transactions[3].Status())
+	assert.Equal(t, core.StatusUnknown, Transaction{}.Status())
+}
+
+func TestLedgerListTransactions(t *testing.T) {
+	rawLedger := core.ReadAllBlocks("xrp")[0]
+	ledger, _ := ParseRawLedger(rawLedger)
+	transactions := ledger.ListTransactions()
+	assert.Len(t, transactions, ledger.TransactionsCount())
+	assert.Equal(t, 2, transactions[2].Index)
+	assert.Equal(t, "rDsbeomae4FXwgQTJp9Rs64Qg9vDiTCdBv", transactions[2].Signer)
+	assert.NotEmpty(t, transactions[2].ID)
+	assert.Len(t, transactions[2].Actions, 1)
+}